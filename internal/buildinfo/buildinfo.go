@@ -0,0 +1,64 @@
+// Package buildinfo describes the build of the release binary
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+// Set when building releases with
+// -ldflags "-X github.com/sanjP10/release/internal/buildinfo.Version=3.2.2 -X ...buildinfo.Commit=<hash> -X ...buildinfo.Date=<date>"
+var (
+	Version string
+	Commit  string
+	Date    string
+)
+
+// DefaultVersion is reported by builds without a version, it is kept in line with the top version of CHANGELOG.md
+const DefaultVersion = "3.2.2"
+
+// Info of the build
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	Date      string `json:"date,omitempty"`
+	GoVersion string `json:"goVersion"`
+}
+
+// Get the build info, values missing from the ldflags are read from the module version and VCS information embedded
+// by go install and go build
+func Get() Info {
+	info, ok := debug.ReadBuildInfo()
+	return resolve(info, ok)
+}
+
+func resolve(info *debug.BuildInfo, ok bool) Info {
+	result := Info{Version: strings.TrimPrefix(Version, "v"), Commit: Commit, Date: Date, GoVersion: runtime.Version()}
+	if ok {
+		// local builds are (devel), go install module@version embeds the version
+		if result.Version == "" && info.Main.Version != "" && info.Main.Version != "(devel)" {
+			result.Version = strings.TrimPrefix(info.Main.Version, "v")
+		}
+		settings := map[string]string{}
+		for _, setting := range info.Settings {
+			settings[setting.Key] = setting.Value
+		}
+		if result.Commit == "" && settings["vcs.revision"] != "" {
+			result.Commit = settings["vcs.revision"]
+			if settings["vcs.modified"] == "true" {
+				result.Commit += "-dirty"
+			}
+		}
+		if result.Date == "" {
+			result.Date = settings["vcs.time"]
+		}
+		if info.GoVersion != "" {
+			result.GoVersion = info.GoVersion
+		}
+	}
+	if result.Version == "" {
+		result.Version = DefaultVersion
+	}
+	return result
+}
@@ -0,0 +1,49 @@
+package buildinfo
+
+import (
+	"github.com/sanjP10/release/pkg/release"
+	"github.com/stretchr/testify/assert"
+	"runtime/debug"
+	"testing"
+)
+
+func TestDefaultVersionMatchesChangelog(t *testing.T) {
+	changelog, err := release.ReadChangelog("../../CHANGELOG.md")
+	assert.NoError(t, err)
+	latest, _ := changelog.Latest()
+	assert.Equal(t, latest.Version, DefaultVersion,
+		"DefaultVersion must match the top version of CHANGELOG.md")
+}
+
+func TestResolveLdflags(t *testing.T) {
+	assertTest := assert.New(t)
+	Version, Commit, Date = "v3.3.0", "abc123", "2026-01-02T03:04:05Z"
+	defer func() { Version, Commit, Date = "", "", "" }()
+	info := resolve(&debug.BuildInfo{
+		GoVersion: "go1.24.0",
+		Main:      debug.Module{Version: "v3.2.0"},
+		Settings:  []debug.BuildSetting{{Key: "vcs.revision", Value: "def456"}, {Key: "vcs.time", Value: "2025-01-01T00:00:00Z"}},
+	}, true)
+	assertTest.Equal(Info{Version: "3.3.0", Commit: "abc123", Date: "2026-01-02T03:04:05Z", GoVersion: "go1.24.0"}, info)
+}
+
+func TestResolveBuildInfo(t *testing.T) {
+	assertTest := assert.New(t)
+	info := resolve(&debug.BuildInfo{
+		GoVersion: "go1.24.0",
+		Main:      debug.Module{Version: "v3.2.1"},
+		Settings: []debug.BuildSetting{
+			{Key: "vcs.revision", Value: "def456"},
+			{Key: "vcs.time", Value: "2025-01-01T00:00:00Z"},
+			{Key: "vcs.modified", Value: "true"},
+		},
+	}, true)
+	assertTest.Equal(Info{Version: "3.2.1", Commit: "def456-dirty", Date: "2025-01-01T00:00:00Z", GoVersion: "go1.24.0"}, info)
+
+	info = resolve(&debug.BuildInfo{Main: debug.Module{Version: "(devel)"}}, true)
+	assertTest.Equal(DefaultVersion, info.Version)
+	assertTest.Empty(info.Commit)
+	assertTest.NotEmpty(info.GoVersion)
+
+	assertTest.Equal(DefaultVersion, resolve(nil, false).Version)
+}
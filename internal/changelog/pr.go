@@ -0,0 +1,91 @@
+package changelog
+
+import (
+	"fmt"
+	"github.com/hashicorp/go-version"
+	"github.com/sanjP10/release/pkg/release"
+	"strings"
+)
+
+// PRCheck is the result of comparing the changelog of a pull request with the changelog of its base branch
+type PRCheck struct {
+	// BaseVersion is the top version of the base changelog, empty when it has no versions
+	BaseVersion string
+	// NewVersion is the version added by the pull request, empty when only Unreleased changed
+	NewVersion string
+	// UnreleasedChanged is set when the notes of the Unreleased section were added, edited or removed
+	UnreleasedChanged bool
+	// Problems that fail the check
+	Problems []string
+}
+
+// Passed reports whether the changelog of the pull request is valid
+func (p PRCheck) Passed() bool {
+	return len(p.Problems) == 0
+}
+
+// CheckPR verifies the pull request adds exactly one version greater than the top version of the base, or only edits
+// Unreleased, and that the versions already on the base were not modified or removed
+func CheckPR(base string, head string) PRCheck {
+	check := PRCheck{}
+	baseChangelog := release.ParseChangelog(base)
+	headChangelog := release.ParseChangelog(head)
+	check.UnreleasedChanged = baseChangelog.Unreleased != headChangelog.Unreleased
+	if latest, ok := baseChangelog.Latest(); ok {
+		check.BaseVersion = latest.Version
+	}
+
+	released := map[string]release.Entry{}
+	for _, entry := range baseChangelog.Entries {
+		released[entry.Version] = entry
+	}
+	var added []string
+	kept := map[string]bool{}
+	for _, entry := range headChangelog.Entries {
+		existing, ok := released[entry.Version]
+		if !ok {
+			added = append(added, entry.Version)
+			continue
+		}
+		kept[entry.Version] = true
+		if existing.Notes != entry.Notes {
+			check.Problems = append(check.Problems, "version "+entry.Version+" was modified, released versions must not change")
+		}
+	}
+	for _, entry := range baseChangelog.Entries {
+		if !kept[entry.Version] {
+			check.Problems = append(check.Problems, "version "+entry.Version+" was removed")
+		}
+	}
+
+	switch {
+	case len(added) > 1:
+		check.Problems = append(check.Problems, fmt.Sprintf("%d versions were added (%s), add exactly one version", len(added), strings.Join(added, ", ")))
+	case len(added) == 1:
+		check.NewVersion = added[0]
+		check.Problems = append(check.Problems, newVersionProblems(check.NewVersion, check.BaseVersion, headChangelog.Entries[0].Version)...)
+	case !check.UnreleasedChanged:
+		check.Problems = append(check.Problems, "no version was added, add a new version or changes to Unreleased")
+	}
+	return check
+}
+
+// newVersionProblems checks the new version is the top version and greater than the top version of the base
+func newVersionProblems(newVersion string, baseVersion string, topVersion string) []string {
+	var problems []string
+	if topVersion != newVersion {
+		problems = append(problems, "version "+newVersion+" must be added above version "+topVersion)
+	}
+	desired, err := version.NewVersion(newVersion)
+	if err != nil {
+		return append(problems, "version "+newVersion+" is not a valid version")
+	}
+	if baseVersion == "" {
+		return problems
+	}
+	previous, err := version.NewVersion(baseVersion)
+	if err == nil && !desired.GreaterThan(previous) {
+		problems = append(problems, "version "+newVersion+" must be greater than "+baseVersion)
+	}
+	return problems
+}
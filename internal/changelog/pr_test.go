@@ -0,0 +1,102 @@
+package changelog
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+const baseChangelog = `# Changelog
+
+## Unreleased
+
+## 1.1.0
+### Updated
+* An update happened
+
+##1.0.0
+### Added
+* Initial release
+`
+
+func TestCheckPRNewVersion(t *testing.T) {
+	assertTest := assert.New(t)
+	head := `# Changelog
+
+## Unreleased
+
+## 1.2.0
+### Added
+* A feature
+
+## 1.1.0
+### Updated
+* An update happened
+
+##1.0.0
+### Added
+* Initial release
+`
+	check := CheckPR(baseChangelog, head)
+	assertTest.True(check.Passed(), check.Problems)
+	assertTest.Equal("1.2.0", check.NewVersion)
+	assertTest.Equal("1.1.0", check.BaseVersion)
+	assertTest.False(check.UnreleasedChanged)
+}
+
+func TestCheckPRUnreleased(t *testing.T) {
+	assertTest := assert.New(t)
+	head := `# Changelog
+
+## [Unreleased]
+* A feature
+
+## 1.1.0
+### Updated
+* An update happened
+
+##1.0.0
+### Added
+* Initial release
+`
+	check := CheckPR(baseChangelog, head)
+	assertTest.True(check.Passed(), check.Problems)
+	assertTest.Equal("", check.NewVersion)
+	assertTest.True(check.UnreleasedChanged)
+
+	check = CheckPR(baseChangelog, baseChangelog)
+	assertTest.Equal([]string{"no version was added, add a new version or changes to Unreleased"}, check.Problems)
+}
+
+func TestCheckPRProblems(t *testing.T) {
+	assertTest := assert.New(t)
+	head := `# Changelog
+
+## 1.0.1
+* A fix
+
+## 1.1.0
+### Updated
+* An update happened
+* Rewritten history
+`
+	check := CheckPR(baseChangelog, head)
+	assertTest.Equal([]string{
+		"version 1.1.0 was modified, released versions must not change",
+		"version 1.0.0 was removed",
+		"version 1.0.1 must be greater than 1.1.0",
+	}, check.Problems)
+
+	head = "## 1.3.0\n\n## 1.2.0\n\n" + baseChangelog
+	check = CheckPR(baseChangelog, head)
+	assertTest.Equal([]string{"2 versions were added (1.3.0, 1.2.0), add exactly one version"}, check.Problems)
+
+	head = "## 1.1.0\n### Updated\n* An update happened\n\n## 1.2.0\n\n##1.0.0\n### Added\n* Initial release\n"
+	check = CheckPR(baseChangelog, head)
+	assertTest.Equal([]string{"version 1.2.0 must be added above version 1.1.0"}, check.Problems)
+}
+
+func TestCheckPRFirstVersion(t *testing.T) {
+	check := CheckPR("", "# Changelog\n\n## 0.1.0\n* Initial release\n")
+	assert.True(t, check.Passed(), check.Problems)
+	assert.Equal(t, "0.1.0", check.NewVersion)
+}
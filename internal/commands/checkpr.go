@@ -0,0 +1,251 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"github.com/google/subcommands"
+	"github.com/sanjP10/release/internal/changelog"
+	"github.com/sanjP10/release/internal/tag/providers/git"
+	"github.com/sanjP10/release/pkg/release"
+	"os"
+	"strings"
+)
+
+// CheckPR for check-pr sub command
+type CheckPR struct {
+	clientFlags
+	authFlags
+	componentFlags
+	username              string
+	password              string
+	changelog             string
+	repo                  string
+	hash                  string
+	base                  string
+	host                  string
+	origin                string
+	provider              string
+	ssh                   string
+	repoPath              string
+	httpAuth              string
+	knownHosts            string
+	insecureIgnoreHostKey bool
+}
+
+// prResult is the outcome of checking the changelog of a component in a pull request
+type prResult struct {
+	component    component
+	check        changelog.PRCheck
+	newChangelog bool
+}
+
+// Name of sub command
+func (*CheckPR) Name() string { return "check-pr" }
+
+// Synopsis of sub command
+func (*CheckPR) Synopsis() string {
+	return "Checks the changelog of a pull request against its base branch."
+}
+
+// Usage of sub command
+func (*CheckPR) Usage() string {
+	return "Checks the changelog of a pull request adds one new version, or only changes Unreleased, compared to its base branch. The result is written as markdown for a pull request comment.\n"
+}
+
+// SetFlags required for check-pr sub command
+func (p *CheckPR) SetFlags(f *flag.FlagSet) {
+	f.StringVar(&p.username, "username", "", "Username (gitlab provider does not require this field). If using ssh provide a username is not git")
+	f.StringVar(&p.password, "password", "", "Password or API token (gitlab provider requires an api token). If using a ssh key please provide the password for your ssh key if password protected")
+	f.StringVar(&p.repo, "repo", "", "The repo name, this should include the organisation or owner, required when a provider is supplied")
+	f.StringVar(&p.changelog, "changelog", "", "Location of changelog markdown file, relative to the root of the repository")
+	f.StringVar(&p.base, "base", "", "The branch, tag or commit the pull request is merged into, e.g. main")
+	f.StringVar(&p.hash, "hash", "", "The head commit of the pull request, the changelog is read from the working directory when not provided")
+	f.StringVar(&p.host, "host", "", "The host for self hosted instances of the allowed providers")
+	f.StringVar(&p.origin, "origin", "", "HTTPS or SSH origin of git repository, to be provided when the provider flag is not provided")
+	f.StringVar(&p.provider, "provider", "", "The Git provider, options are github, gitlab or bitbucket, when providing this flag the changelogs are read with their APIs")
+	f.StringVar(&p.ssh, "ssh", "", "SSH private key file location, please provide Username and password of the SSH file if required. Username defaults to git. This is to be used when the provider flag is not provided")
+	f.StringVar(&p.httpAuth, "http-auth", "basic", "Authentication scheme for HTTPS origins, options are basic or bearer (the password is sent as the token). This is to be used when the provider flag is not provided")
+	f.StringVar(&p.knownHosts, "known-hosts", "", "known_hosts file used to verify the SSH host key, defaults to SSH_KNOWN_HOSTS or ~/.ssh/known_hosts. This is to be used when the provider flag is not provided")
+	f.BoolVar(&p.insecureIgnoreHostKey, "insecure-ignore-host-key", false, "Skip SSH host key verification, only use this in throwaway environments")
+	f.StringVar(&p.repoPath, "repo-path", "", "Path to an existing local clone, the base is read from it and only fetched from its origin when missing. This is to be used when the provider flag is not provided")
+	p.setComponentFlags(f)
+	p.setAuthFlags(f)
+	p.setClientFlags(f)
+}
+
+// Execute flow for check-pr sub command
+func (p *CheckPR) Execute(ctx context.Context, _ *flag.FlagSet, _ ...interface{}) subcommands.ExitStatus {
+	exit := subcommands.ExitSuccess
+	errors := checkPRFlags(p)
+	if len(errors) > 0 {
+		errors = append(errors, "\n")
+		exit = subcommands.ExitUsageError
+		_, err := os.Stderr.WriteString("missing flags for check-pr:\n" + strings.Join(errors, "\n"))
+		if err != nil {
+			panic("Cannot write to stderr")
+		}
+		return exit
+	}
+	ctx, cancel := p.withDeadline(ctx)
+	defer cancel()
+	components, err := p.components(p.changelog)
+	if err != nil {
+		_, err := os.Stderr.WriteString(err.Error() + "\n")
+		if err != nil {
+			panic("Cannot write to stderr")
+		}
+		return subcommands.ExitUsageError
+	}
+	reader, err := newFileReader(p)
+	if err != nil {
+		_, err := os.Stderr.WriteString("Error reading repository " + p.repoName() + " " + err.Error() + "\n")
+		if err != nil {
+			panic("Cannot write to stderr")
+		}
+		return subcommands.ExitFailure
+	}
+	var results []prResult
+	for _, current := range components {
+		results = append(results, p.checkComponent(ctx, reader, current))
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	if reportInterrupted(ctx, "reading changelogs") {
+		return subcommands.ExitFailure
+	}
+	_, err = os.Stdout.WriteString(prComment(p.base, results))
+	if err != nil {
+		panic("Cannot write to stdout")
+	}
+	for _, result := range results {
+		if !result.check.Passed() {
+			exit = subcommands.ExitFailure
+		}
+	}
+	return exit
+}
+
+// checkComponent compares the changelog of the component on the base with the pull request, a changelog missing
+// from the base is new
+func (p *CheckPR) checkComponent(ctx context.Context, reader release.FileReader, current component) prResult {
+	result := prResult{component: current}
+	base, err := reader.ReadFile(ctx, p.base, current.repoChangelog())
+	if errors.Is(err, release.ErrFileNotFound) {
+		result.newChangelog = true
+	} else if err != nil {
+		result.check.Problems = []string{"unable to read the changelog from " + p.base + ", " + err.Error()}
+		return result
+	}
+	var head string
+	if len(p.hash) > 0 {
+		head, err = reader.ReadFile(ctx, p.hash, current.repoChangelog())
+	} else {
+		var content []byte
+		content, err = os.ReadFile(current.changelog)
+		head = string(content)
+	}
+	if err != nil {
+		result.check.Problems = []string{"unable to read the changelog of the pull request, " + err.Error()}
+		return result
+	}
+	result.check = changelog.CheckPR(base, head)
+	return result
+}
+
+// repoName describes the repository for error messages
+func (p *CheckPR) repoName() string {
+	switch {
+	case len(p.provider) > 0:
+		return p.repo
+	case len(p.origin) > 0:
+		return p.origin
+	}
+	return p.repoPath
+}
+
+// prComment describes the results as markdown for a pull request comment
+func prComment(base string, results []prResult) string {
+	heading := "### Changelog check passed"
+	var lines []string
+	for _, result := range results {
+		name := "`" + result.component.repoChangelog() + "`"
+		if !result.check.Passed() {
+			heading = "### Changelog check failed"
+			lines = append(lines, "* "+name+" compared with `"+base+"`:")
+			for _, problem := range result.check.Problems {
+				lines = append(lines, "  * "+problem)
+			}
+			continue
+		}
+		switch {
+		case result.newChangelog:
+			lines = append(lines, "* "+name+" is a new changelog starting at version "+result.check.NewVersion)
+		case result.check.NewVersion == "":
+			lines = append(lines, "* "+name+" only changes Unreleased")
+		case result.check.BaseVersion == "":
+			lines = append(lines, "* "+name+" adds version "+result.check.NewVersion)
+		default:
+			lines = append(lines, "* "+name+" adds version "+result.check.NewVersion+", the latest version on `"+base+"` is "+result.check.BaseVersion)
+		}
+	}
+	return heading + "\n\n" + strings.Join(lines, "\n") + "\n"
+}
+
+func checkPRFlags(p *CheckPR) []string {
+	var errors []string
+	if len(p.provider) == 0 {
+		// Use regular git, a local clone provides its own origin and credentials
+		if len(p.origin) == 0 && len(p.repoPath) == 0 {
+			errors = append(errors, "-origin or -repo-path required")
+		}
+	} else if ValidProvider(p.provider) {
+		// for valid providers check for the credentials of the authentication type and repo
+		errors = append(errors, p.checkAuthFlags(p.provider, p.username, p.password)...)
+		if len(p.repo) == 0 {
+			errors = append(errors, "-repo required")
+		}
+		errors = append(errors, checkRepoFlag(p.provider, p.repo, p.host)...)
+	} else {
+		// valid provider values
+		errors = append(errors, "-provider valid values are "+strings.Join(providers[:], ", "))
+	}
+	if !git.ValidHTTPAuth(p.httpAuth) {
+		errors = append(errors, "-http-auth valid values are "+git.HTTPAuthBasic+", "+git.HTTPAuthBearer)
+	}
+	errors = append(errors, p.checkClientFlags()...)
+	// changelog and base are mandatory
+	if len(p.changelog) == 0 && len(p.configFile) == 0 {
+		errors = append(errors, "-changelog required")
+	}
+	if len(p.base) == 0 {
+		errors = append(errors, "-base required")
+	}
+	return errors
+}
+
+// newFileReader sets up the provider or git repository the changelogs are read with
+func newFileReader(p *CheckPR) (release.FileReader, error) {
+	if len(p.provider) > 0 {
+		client, err := p.releaseClient(&p.clientFlags, p.provider, p.username, p.password, p.repo, p.host)
+		if err != nil {
+			return nil, err
+		}
+		return client.(release.FileReader), nil
+	}
+	client, err := release.NewGit(release.GitOptions{
+		Origin:                p.origin,
+		RepoPath:              p.repoPath,
+		Username:              p.username,
+		Password:              p.password,
+		BearerToken:           strings.ToLower(p.httpAuth) == git.HTTPAuthBearer,
+		SSHKey:                p.ssh,
+		KnownHosts:            p.knownHosts,
+		InsecureIgnoreHostKey: p.insecureIgnoreHostKey,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return client.(release.FileReader), nil
+}
@@ -0,0 +1,71 @@
+package commands
+
+import (
+	"context"
+	"github.com/google/subcommands"
+	"github.com/sanjP10/release/internal/changelog"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func TestCheckPR_Name(t *testing.T) {
+	checkPR := &CheckPR{}
+	assert.Equal(t, "check-pr", checkPR.Name())
+}
+
+func Test_checkPRFlags(t *testing.T) {
+	assertTest := assert.New(t)
+	checkPR := &CheckPR{}
+	checkPR.httpAuth = "basic"
+	assertTest.Equal([]string{"-origin or -repo-path required", "-changelog required", "-base required"}, checkPRFlags(checkPR))
+
+	checkPR.provider = "github"
+	checkPR.authType = "bearer"
+	checkPR.password = "token"
+	checkPR.repo = "owner/repo"
+	checkPR.changelog = "CHANGELOG.md"
+	checkPR.base = "main"
+	assertTest.Empty(checkPRFlags(checkPR))
+}
+
+func Test_prComment(t *testing.T) {
+	assertTest := assert.New(t)
+	results := []prResult{
+		{component: component{changelog: "CHANGELOG.md"}, check: changelog.PRCheck{BaseVersion: "1.1.0", NewVersion: "1.2.0"}},
+		{component: component{name: "auth", changelog: "auth/CHANGELOG.md"}, check: changelog.PRCheck{UnreleasedChanged: true}},
+	}
+	assertTest.Equal("### Changelog check passed\n\n"+
+		"* `CHANGELOG.md` adds version 1.2.0, the latest version on `main` is 1.1.0\n"+
+		"* `auth/CHANGELOG.md` only changes Unreleased\n", prComment("main", results))
+
+	results[1].check.Problems = []string{"version 1.0.0 was removed"}
+	assertTest.Equal("### Changelog check failed\n\n"+
+		"* `CHANGELOG.md` adds version 1.2.0, the latest version on `main` is 1.1.0\n"+
+		"* `auth/CHANGELOG.md` compared with `main`:\n"+
+		"  * version 1.0.0 was removed\n", prComment("main", results))
+}
+
+func Test_CheckPRGit(t *testing.T) {
+	assertTest := assert.New(t)
+	originPath, clonePath, _ := initOrigin(t)
+	base := pushFiles(t, clonePath, map[string]string{"CHANGELOG.md": "# Changelog\n\n## 1.0.0\n* Initial release\n"})
+	head := pushFiles(t, clonePath, map[string]string{"CHANGELOG.md": "# Changelog\n\n## 1.1.0\n* A feature\n\n## 1.0.0\n* Initial release\n"})
+	t.Chdir(t.TempDir())
+
+	checkPR := &CheckPR{}
+	checkPR.origin = originPath
+	checkPR.httpAuth = "basic"
+	checkPR.changelog = "CHANGELOG.md"
+	checkPR.base = base.String()
+	checkPR.hash = head.String()
+	assertTest.Equal(subcommands.ExitSuccess, checkPR.Execute(context.Background(), nil))
+
+	// the pull request is read from the working directory without -hash, the base is fetched by branch
+	checkPR.hash = ""
+	checkPR.base = "master"
+	assertTest.NoError(os.WriteFile("CHANGELOG.md", []byte("# Changelog\n\n## 1.2.0\n* Another feature\n\n## 1.1.0\n* A rewritten feature\n\n## 1.0.0\n* Initial release\n"), 0600))
+	assertTest.Equal(subcommands.ExitFailure, checkPR.Execute(context.Background(), nil))
+	assertTest.NoError(os.WriteFile("CHANGELOG.md", []byte("# Changelog\n\n## 1.2.0\n* Another feature\n\n## 1.1.0\n* A feature\n\n## 1.0.0\n* Initial release\n"), 0600))
+	assertTest.Equal(subcommands.ExitSuccess, checkPR.Execute(context.Background(), nil))
+}
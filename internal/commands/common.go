@@ -1,7 +1,17 @@
 package commands
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"github.com/sanjP10/release/internal/tag"
+	"github.com/sanjP10/release/internal/tag/providers/bitbucket"
+	"github.com/sanjP10/release/internal/tag/providers/github"
+	"github.com/sanjP10/release/internal/tag/providers/gitlab"
+	"github.com/sanjP10/release/pkg/release"
+	"os"
 	"strings"
+	"time"
 )
 
 var providers = [...]string{"github", "gitlab", "bitbucket"}
@@ -17,3 +27,182 @@ func ValidProvider(str string) bool {
 	}
 	return isValid
 }
+
+// authFlags select how providers authenticate with their APIs
+type authFlags struct {
+	authType          string
+	appID             string
+	appKey            string
+	appInstallationID string
+}
+
+func (a *authFlags) setAuthFlags(f *flag.FlagSet) {
+	f.StringVar(&a.authType, "auth-type", "", "Provider API authentication. github: basic (default) with -username and -password, bearer with -password as the token, or app with -app-id and -app-key. "+
+		"gitlab: private-token (default), job-token using -password or CI_JOB_TOKEN, or bearer for OAuth tokens, CI_JOB_TOKEN is used when no -password is provided. "+
+		"bitbucket: basic (default) with -username and -password, or bearer with -password as an HTTP access token")
+	f.StringVar(&a.appID, "app-id", "", "GitHub App ID used with -auth-type app, releases are attributed to the app")
+	f.StringVar(&a.appKey, "app-key", "", "GitHub App private key file used with -auth-type app")
+	f.StringVar(&a.appInstallationID, "app-installation-id", "", "GitHub App installation ID, looked up from -repo when not provided")
+}
+
+// checkAuthFlags checks the credentials required by the authentication type of the provider
+func (a *authFlags) checkAuthFlags(provider string, username string, password string) []string {
+	var errors []string
+	authType := strings.ToLower(a.authType)
+	switch strings.ToLower(provider) {
+	case "github":
+		if !github.ValidAuthType(authType) {
+			return append(errors, "-auth-type valid values for github are "+github.AuthBasic+", "+github.AuthBearer+", "+github.AuthApp)
+		}
+		if authType == github.AuthApp {
+			if len(a.appID) == 0 {
+				errors = append(errors, "-app-id required")
+			}
+			if len(a.appKey) == 0 {
+				errors = append(errors, "-app-key required")
+			}
+			return errors
+		}
+		if len(username) == 0 && authType != github.AuthBearer {
+			errors = append(errors, "-username required")
+		}
+	case "gitlab":
+		if !gitlab.ValidAuthType(authType) {
+			return append(errors, "-auth-type valid values for gitlab are "+gitlab.AuthPrivateToken+", "+gitlab.AuthJobToken+", "+gitlab.AuthBearer)
+		}
+		// inside GitLab CI the job token is used when no password is provided
+		if len(password) == 0 && (authType == "" || authType == gitlab.AuthJobToken) && gitlab.JobTokenAvailable() {
+			return errors
+		}
+	case "bitbucket":
+		if !bitbucket.ValidAuthType(authType) {
+			return append(errors, "-auth-type valid values for bitbucket are "+bitbucket.AuthBasic+", "+bitbucket.AuthBearer)
+		}
+		if len(username) == 0 && authType != bitbucket.AuthBearer {
+			errors = append(errors, "-username required")
+		}
+	}
+	if len(password) == 0 {
+		errors = append(errors, "-password required")
+	}
+	return errors
+}
+
+// checkRepoFlag checks the repo format of self-hosted Bitbucket, which is used to build its API paths
+func checkRepoFlag(provider string, repo string, host string) []string {
+	var errors []string
+	if strings.ToLower(provider) == "bitbucket" && len(host) > 0 && len(repo) > 0 {
+		if _, err := bitbucket.ServerRepoPath(repo); err != nil {
+			errors = append(errors, "-"+err.Error())
+		}
+	}
+	return errors
+}
+
+// releaseClient builds the client of a provider API with the authentication and client flags
+func (a *authFlags) releaseClient(c *clientFlags, provider string, username string, password string, repo string, host string) (release.Client, error) {
+	httpClient, err := tag.NewHTTPClient(c.clientOptions())
+	if err != nil {
+		return nil, err
+	}
+	httpOptions := release.HTTPOptions{Client: httpClient, Retry: release.RetryPolicy(c.retryPolicy())}
+	authType := strings.ToLower(a.authType)
+	switch strings.ToLower(provider) {
+	case "github":
+		options := release.GitHubOptions{Repo: repo, Host: host, Username: username, Token: password, HTTP: httpOptions}
+		switch authType {
+		case github.AuthBearer:
+			options.Username = ""
+		case github.AuthApp:
+			options.AppID, options.AppKey, options.InstallationID = a.appID, a.appKey, a.appInstallationID
+		}
+		return release.NewGitHub(options)
+	case "gitlab":
+		return release.NewGitLab(release.GitLabOptions{Repo: repo, Host: host, Token: password, TokenType: authType, HTTP: httpOptions})
+	default:
+		if authType == bitbucket.AuthBearer {
+			username = ""
+		}
+		return release.NewBitbucket(release.BitbucketOptions{Repo: repo, Host: host, Username: username, Password: password, HTTP: httpOptions})
+	}
+}
+
+// clientFlags configure the HTTP client used to call provider APIs
+type clientFlags struct {
+	timeout            time.Duration
+	deadline           time.Duration
+	caCert             string
+	clientCert         string
+	clientKey          string
+	insecureSkipVerify bool
+	retries            int
+	retryDelay         time.Duration
+	retryMaxDelay      time.Duration
+}
+
+func (c *clientFlags) setClientFlags(f *flag.FlagSet) {
+	f.DurationVar(&c.timeout, "timeout", time.Minute, "Timeout for each attempt of a provider API request, e.g. 30s, waiting to retry is not included. Proxies are configured with HTTPS_PROXY and NO_PROXY")
+	f.DurationVar(&c.deadline, "deadline", 0, "Deadline for the whole command, e.g. 5m, in flight requests are cancelled when it passes. 0 waits indefinitely")
+	f.StringVar(&c.caCert, "ca-cert", "", "PEM encoded CA bundle to trust in addition to the system roots, for self hosted providers with a private CA")
+	f.StringVar(&c.clientCert, "client-cert", "", "PEM encoded client certificate for mutual TLS with the provider, requires -client-key")
+	f.StringVar(&c.clientKey, "client-key", "", "PEM encoded private key of the client certificate")
+	f.BoolVar(&c.insecureSkipVerify, "insecure-skip-verify", false, "Skip TLS certificate verification of the provider, only use this in throwaway environments")
+	f.IntVar(&c.retries, "retries", 3, "Number of retries for provider API requests failing with network errors, 5xx or rate limit responses")
+	f.DurationVar(&c.retryDelay, "retry-delay", time.Second, "Initial delay between retries, doubled on each retry")
+	f.DurationVar(&c.retryMaxDelay, "retry-max-delay", time.Minute, "Longest delay between retries, rate limits resetting later than this are not waited for")
+}
+
+func (c *clientFlags) checkClientFlags() []string {
+	var errors []string
+	if c.timeout < 0 || c.deadline < 0 {
+		errors = append(errors, "-timeout and -deadline cannot be negative")
+	}
+	if (len(c.clientCert) == 0) != (len(c.clientKey) == 0) {
+		errors = append(errors, "-client-cert and -client-key must be provided together")
+	}
+	return errors
+}
+
+func (c *clientFlags) clientOptions() tag.ClientOptions {
+	return tag.ClientOptions{
+		Timeout:            c.timeout,
+		CACert:             c.caCert,
+		ClientCert:         c.clientCert,
+		ClientKey:          c.clientKey,
+		InsecureSkipVerify: c.insecureSkipVerify,
+		Retry:              c.retryPolicy(),
+	}
+}
+
+func (c *clientFlags) retryPolicy() tag.RetryPolicy {
+	return tag.RetryPolicy{
+		MaxRetries: c.retries,
+		BaseDelay:  c.retryDelay,
+		MaxDelay:   c.retryMaxDelay,
+	}
+}
+
+// withDeadline applies the -deadline to the whole command
+func (c *clientFlags) withDeadline(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.deadline <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, c.deadline)
+}
+
+// reportInterrupted reports the step that was stopped when the command was cancelled by a signal or the -deadline
+func reportInterrupted(ctx context.Context, step string) bool {
+	err := ctx.Err()
+	if err == nil {
+		return false
+	}
+	reason := "Interrupted"
+	if errors.Is(err, context.DeadlineExceeded) {
+		reason = "Timed out"
+	}
+	_, err = os.Stderr.WriteString(reason + " while " + step + "\n")
+	if err != nil {
+		panic("Cannot write to stderr")
+	}
+	return true
+}
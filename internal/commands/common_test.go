@@ -1,8 +1,10 @@
 package commands
 
 import (
+	"context"
 	"github.com/stretchr/testify/assert"
 	"testing"
+	"time"
 )
 
 func Test_InvalidProvider(t *testing.T) {
@@ -39,3 +41,39 @@ func Test_ValidProvider(t *testing.T) {
 	provider = "bitbucket"
 	assertTest.True(ValidProvider(provider))
 }
+
+func Test_WithDeadline(t *testing.T) {
+	assertTest := assert.New(t)
+	flags := &clientFlags{}
+	ctx, cancel := flags.withDeadline(context.Background())
+	defer cancel()
+	_, hasDeadline := ctx.Deadline()
+	assertTest.False(hasDeadline)
+
+	flags.deadline = time.Minute
+	ctx, cancel = flags.withDeadline(context.Background())
+	defer cancel()
+	_, hasDeadline = ctx.Deadline()
+	assertTest.True(hasDeadline)
+}
+
+func Test_ReportInterrupted(t *testing.T) {
+	assertTest := assert.New(t)
+	assertTest.False(reportInterrupted(context.Background(), "creating tag"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assertTest.True(reportInterrupted(ctx, "creating tag"))
+
+	ctx, cancel = context.WithTimeout(context.Background(), -time.Second)
+	defer cancel()
+	assertTest.True(reportInterrupted(ctx, "creating tag"))
+}
+
+func Test_CheckClientFlags_Timeout(t *testing.T) {
+	assertTest := assert.New(t)
+	flags := &clientFlags{timeout: -time.Second}
+	assertTest.Equal([]string{"-timeout and -deadline cannot be negative"}, flags.checkClientFlags())
+	flags = &clientFlags{deadline: -time.Second}
+	assertTest.Equal([]string{"-timeout and -deadline cannot be negative"}, flags.checkClientFlags())
+}
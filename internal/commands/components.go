@@ -0,0 +1,266 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/google/subcommands"
+	"github.com/sanjP10/release/internal/config"
+	"github.com/sanjP10/release/internal/tag"
+	"github.com/sanjP10/release/pkg/release"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Placeholders in tag templates
+const (
+	versionPlaceholder   = "{version}"
+	componentPlaceholder = "{component}"
+)
+
+// Outcomes of releasing a component
+const (
+	outcomeReleased = "released"
+	outcomeReady    = "ready"
+	outcomeSkipped  = "skipped"
+	outcomeFailed   = "failed"
+)
+
+// componentFlags select the changelogs released in one run
+type componentFlags struct {
+	configFile  string
+	tagTemplate string
+	// loaded is the config file read by components
+	loaded config.Config
+}
+
+func (cf *componentFlags) setComponentFlags(f *flag.FlagSet) {
+	f.StringVar(&cf.configFile, "config", "", "YAML config file listing the components of a monorepo, each with a changelog and optional tag template")
+	f.StringVar(&cf.tagTemplate, "tag-template", "", "Template of the tag built from {version} and {component}, e.g. {component}/v{version}. Defaults to {version}, or {component}/{version} for multiple components")
+}
+
+// component is a changelog released with its own tags, the name is empty when a single changelog is released
+type component struct {
+	name        string
+	changelog   string
+	path        string
+	tagTemplate string
+	// versionFiles configured for the component, or for a single changelog
+	versionFiles []config.VersionFile
+}
+
+// tag builds the tag of a version of the component
+func (c component) tag(version string) string {
+	tagName := strings.ReplaceAll(c.tagTemplate, versionPlaceholder, strings.TrimSpace(version))
+	return strings.ReplaceAll(tagName, componentPlaceholder, c.name)
+}
+
+// label names the component in messages
+func (c component) label() string {
+	if c.name == "" {
+		return c.changelog
+	}
+	return c.name
+}
+
+// repoPath is the directory of the component relative to the root of the repository, the working directory
+func (c component) repoPath() string {
+	return repoRelative(c.path)
+}
+
+// repoChangelog is the changelog of the component relative to the root of the repository
+func (c component) repoChangelog() string {
+	return repoRelative(c.changelog)
+}
+
+// repoRelative makes a path relative to the working directory, which is the root of the repository, with slashes
+func repoRelative(path string) string {
+	if filepath.IsAbs(path) {
+		workingDir, err := os.Getwd()
+		if err == nil {
+			if relative, err := filepath.Rel(workingDir, path); err == nil {
+				path = relative
+			}
+		}
+	}
+	return filepath.ToSlash(filepath.Clean(path))
+}
+
+// components resolves the changelogs to release from the config file, a glob in -changelog or a single changelog
+func (cf *componentFlags) components(changelogFlag string) ([]component, error) {
+	var components []component
+	cfg := config.Config{}
+	if len(cf.configFile) > 0 {
+		var err error
+		cfg, err = config.Load(cf.configFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+	cf.loaded = cfg
+	switch {
+	case len(cfg.Components) > 0:
+		if len(changelogFlag) > 0 {
+			return nil, errors.New("-changelog cannot be used with components in -config")
+		}
+		for _, configured := range cfg.Components {
+			components = append(components, component{
+				name:         configured.Name,
+				changelog:    configured.Changelog,
+				path:         configured.Path,
+				tagTemplate:  configured.TagTemplate,
+				versionFiles: configured.VersionFiles,
+			})
+		}
+	case strings.ContainsAny(changelogFlag, "*?["):
+		matches, err := filepath.Glob(changelogFlag)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -changelog glob %s: %w", changelogFlag, err)
+		}
+		if len(matches) == 0 {
+			return nil, errors.New("no changelogs match " + changelogFlag)
+		}
+		sort.Strings(matches)
+		for _, match := range matches {
+			// components are named after the directory holding their changelog
+			components = append(components, component{name: filepath.Base(filepath.Dir(match)), changelog: match})
+		}
+	case len(changelogFlag) > 0:
+		components = append(components, component{changelog: changelogFlag})
+	default:
+		return nil, errors.New("-changelog required")
+	}
+
+	if len(cfg.VersionFiles) > 0 {
+		if len(components) > 1 {
+			return nil, errors.New("versionFiles in -config are for a single changelog, set them on each component instead")
+		}
+		components[0].versionFiles = cfg.VersionFiles
+	}
+	defaultTemplate := versionPlaceholder
+	if components[0].name != "" {
+		defaultTemplate = componentPlaceholder + "/" + versionPlaceholder
+	}
+	names := map[string]bool{}
+	for i := range components {
+		current := &components[i]
+		// a single changelog covers the whole repository, wherever it is kept
+		if current.path == "" && current.name == "" {
+			current.path = "."
+		} else if current.path == "" {
+			current.path = filepath.Dir(current.changelog)
+		}
+		for _, template := range []string{current.tagTemplate, cf.tagTemplate, cfg.TagTemplate, defaultTemplate} {
+			if template != "" {
+				current.tagTemplate = template
+				break
+			}
+		}
+		if !strings.Contains(current.tagTemplate, versionPlaceholder) {
+			return nil, fmt.Errorf("tag template %s of %s must contain %s", current.tagTemplate, current.label(), versionPlaceholder)
+		}
+		if len(components) > 1 && !strings.Contains(current.tagTemplate, componentPlaceholder) {
+			return nil, fmt.Errorf("tag template %s of %s must contain %s to keep component tags apart", current.tagTemplate, current.label(), componentPlaceholder)
+		}
+		if names[current.name] {
+			return nil, errors.New("duplicate component " + current.name)
+		}
+		names[current.name] = true
+	}
+	return components, nil
+}
+
+// monorepo reports whether the components are reported individually with a summary
+func monorepo(components []component) bool {
+	return len(components) > 1 || (len(components) == 1 && components[0].name != "")
+}
+
+// componentResult is the outcome of releasing a component
+type componentResult struct {
+	component component
+	tag       string
+	outcome   string
+	reason    string
+	exit      subcommands.ExitStatus
+}
+
+// releaseSummary records the outcome of each component of a run
+type releaseSummary struct {
+	results []componentResult
+}
+
+func (s *releaseSummary) add(result componentResult) {
+	s.results = append(s.results, result)
+}
+
+// failed reports whether any component failed
+func (s *releaseSummary) failed() bool {
+	for _, result := range s.results {
+		if result.outcome == outcomeFailed {
+			return true
+		}
+	}
+	return false
+}
+
+// write reports the outcome of each component followed by the totals
+func (s *releaseSummary) write() {
+	counts := map[string]int{}
+	lines := []string{"Summary:"}
+	for _, result := range s.results {
+		counts[result.outcome]++
+		line := fmt.Sprintf("  %-8s %s", result.outcome, result.component.label())
+		if result.tag != "" {
+			line += " " + result.tag
+		}
+		if result.reason != "" {
+			line += " (" + result.reason + ")"
+		}
+		lines = append(lines, line)
+	}
+	var totals []string
+	for _, outcome := range []string{outcomeReleased, outcomeReady, outcomeSkipped, outcomeFailed} {
+		if counts[outcome] > 0 {
+			totals = append(totals, fmt.Sprintf("%d %s", counts[outcome], outcome))
+		}
+	}
+	lines = append(lines, strings.Join(totals, ", "))
+	_, err := os.Stderr.WriteString(strings.Join(lines, "\n") + "\n")
+	if err != nil {
+		panic("Cannot write to stderr")
+	}
+}
+
+// unchangedSinceTag compares the files of the component with the existing tag of its top changelog version, an
+// unchanged component is skipped and a changed component fails as it needs a new changelog version. Globs narrow the
+// files of the component that count as changes
+func unchangedSinceTag(ctx context.Context, client release.Client, releaseTag release.Tag, result componentResult, globs ...string) componentResult {
+	files, err := client.ChangedFiles(ctx, releaseTag)
+	filesOf := "files"
+	if result.component.name != "" {
+		filesOf = "files in " + result.component.name
+	}
+	var message string
+	switch {
+	case reportInterrupted(ctx, "comparing "+result.component.label()+" with tag "+result.tag):
+		result.reason = "interrupted"
+		return result
+	case err != nil:
+		result.reason = "unable to compare changes"
+		message = "Unable to compare " + result.component.label() + " with tag " + result.tag + " " + err.Error() + "\n"
+	case tag.ChangedUnder(files, result.component.repoPath(), globs...):
+		result.reason = "changed without a new version"
+		message = "Tag " + result.tag + " already exists and " + filesOf + " changed since it was created, add a new version entry to " + result.component.changelog + "\n"
+	default:
+		result.outcome, result.exit, result.reason = outcomeSkipped, subcommands.ExitSuccess, "unchanged since tag"
+		message = "Tag " + result.tag + " already exists and no " + filesOf + " changed since it was created, skipping\n"
+	}
+	_, err = os.Stderr.WriteString(message)
+	if err != nil {
+		panic("Cannot write to stderr")
+	}
+	return result
+}
@@ -0,0 +1,291 @@
+package commands
+
+import (
+	"context"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/google/subcommands"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeChangelog(t *testing.T, path string, versions ...string) {
+	content := "# Changelog\n"
+	for _, version := range versions {
+		content += "\n## " + version + "\n\n### Added\n* Changes in " + version + "\n"
+	}
+	err := os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(path, []byte(content), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_ComponentsSingleChangelog(t *testing.T) {
+	assertTest := assert.New(t)
+	flags := componentFlags{}
+	components, err := flags.components("CHANGELOG.md")
+	assertTest.NoError(err)
+	assertTest.Equal([]component{{changelog: "CHANGELOG.md", path: ".", tagTemplate: "{version}"}}, components)
+	assertTest.False(monorepo(components))
+	assertTest.Equal("1.2.0", components[0].tag("1.2.0 "))
+
+	flags.tagTemplate = "v{version}"
+	components, err = flags.components("CHANGELOG.md")
+	assertTest.NoError(err)
+	assertTest.Equal("v1.2.0", components[0].tag("1.2.0"))
+
+	// changes anywhere in the repository count towards a changelog kept in a subdirectory
+	components, err = flags.components(filepath.Join("docs", "CHANGELOG.md"))
+	assertTest.NoError(err)
+	assertTest.Equal(".", components[0].path)
+	assertTest.Equal(".", components[0].repoPath())
+}
+
+func Test_ComponentsGlob(t *testing.T) {
+	assertTest := assert.New(t)
+	dir := t.TempDir()
+	writeChangelog(t, filepath.Join(dir, "services", "billing", "CHANGELOG.md"), "1.3.0")
+	writeChangelog(t, filepath.Join(dir, "services", "auth", "CHANGELOG.md"), "2.0.0")
+
+	flags := componentFlags{tagTemplate: "{component}/v{version}"}
+	components, err := flags.components(filepath.Join(dir, "services", "*", "CHANGELOG.md"))
+	assertTest.NoError(err)
+	assertTest.Len(components, 2)
+	assertTest.True(monorepo(components))
+	assertTest.Equal("auth", components[0].name)
+	assertTest.Equal(filepath.Join(dir, "services", "auth"), components[0].path)
+	assertTest.Equal("billing/v1.3.0", components[1].tag("1.3.0"))
+
+	flags.tagTemplate = "v{version}"
+	_, err = flags.components(filepath.Join(dir, "services", "*", "CHANGELOG.md"))
+	assertTest.ErrorContains(err, "must contain {component}")
+
+	_, err = flags.components(filepath.Join(dir, "missing", "*", "CHANGELOG.md"))
+	assertTest.ErrorContains(err, "no changelogs match")
+}
+
+func Test_ComponentsConfig(t *testing.T) {
+	assertTest := assert.New(t)
+	configPath := filepath.Join(t.TempDir(), "release.yaml")
+	err := os.WriteFile(configPath, []byte(`
+tagTemplate: "{component}/v{version}"
+components:
+  - name: billing
+    changelog: services/billing/CHANGELOG.md
+  - name: auth
+    changelog: services/auth/CHANGELOG.md
+    tagTemplate: "{component}-{version}"
+`), 0600)
+	assertTest.NoError(err)
+
+	flags := componentFlags{configFile: configPath}
+	components, err := flags.components("")
+	assertTest.NoError(err)
+	assertTest.Equal("billing/v1.3.0", components[0].tag("1.3.0"))
+	assertTest.Equal("auth-2.0.0", components[1].tag("2.0.0"))
+	assertTest.Equal("services/billing", components[0].path)
+
+	_, err = flags.components("CHANGELOG.md")
+	assertTest.EqualError(err, "-changelog cannot be used with components in -config")
+}
+
+func Test_ComponentsInvalidTemplate(t *testing.T) {
+	flags := componentFlags{tagTemplate: "release"}
+	_, err := flags.components("CHANGELOG.md")
+	assert.EqualError(t, err, "tag template release of CHANGELOG.md must contain {version}")
+}
+
+func Test_ReleaseSummaryFailed(t *testing.T) {
+	assertTest := assert.New(t)
+	summary := releaseSummary{}
+	summary.add(componentResult{component: component{name: "billing"}, outcome: outcomeReleased})
+	summary.add(componentResult{component: component{name: "auth"}, outcome: outcomeSkipped})
+	assertTest.False(summary.failed())
+	summary.add(componentResult{component: component{name: "payments"}, outcome: outcomeFailed})
+	assertTest.True(summary.failed())
+}
+
+// initOrigin creates a bare origin repository with a single commit pushed from a clone
+func initOrigin(t *testing.T) (string, string, plumbing.Hash) {
+	originPath := t.TempDir()
+	clonePath := t.TempDir()
+	_, err := git.PlainInit(originPath, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clone, err := git.PlainInit(clonePath, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	worktree, _ := clone.Worktree()
+	hash, err := worktree.Commit("initial commit", &git.CommitOptions{
+		AllowEmptyCommits: true,
+		Author:            &object.Signature{Name: "tester", Email: "tester@example.com", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = clone.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{originPath}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = clone.Push(&git.PushOptions{RemoteName: "origin", RefSpecs: []config.RefSpec{"refs/heads/*:refs/heads/*"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return originPath, clonePath, hash
+}
+
+// pushFiles commits the files to the clone and pushes the commit to the origin
+func pushFiles(t *testing.T, clonePath string, files map[string]string) plumbing.Hash {
+	clone, err := git.PlainOpen(clonePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	worktree, _ := clone.Worktree()
+	for name, content := range files {
+		err = os.MkdirAll(filepath.Join(clonePath, filepath.Dir(name)), 0700)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = os.WriteFile(filepath.Join(clonePath, name), []byte(content), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = worktree.Add(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	hash, err := worktree.Commit("update files", &git.CommitOptions{
+		Author: &object.Signature{Name: "tester", Email: "tester@example.com", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = clone.Push(&git.PushOptions{RemoteName: "origin", RefSpecs: []config.RefSpec{"refs/heads/*:refs/heads/*"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hash
+}
+
+func Test_CreateComponents(t *testing.T) {
+	assertTest := assert.New(t)
+	originPath, _, hash := initOrigin(t)
+	dir := t.TempDir()
+	writeChangelog(t, filepath.Join(dir, "billing", "CHANGELOG.md"), "1.3.0", "1.2.0")
+	writeChangelog(t, filepath.Join(dir, "auth", "CHANGELOG.md"), "2.0.0")
+
+	create := &Create{}
+	create.origin = originPath
+	create.email = "tester@example.com"
+	create.username = "tester"
+	create.hash = hash.String()
+	create.changelog = filepath.Join(dir, "*", "CHANGELOG.md")
+	create.tagTemplate = "{component}/v{version}"
+	assertTest.Equal(subcommands.ExitSuccess, create.Execute(context.Background(), nil))
+
+	origin, err := git.PlainOpen(originPath)
+	assertTest.NoError(err)
+	for _, tagName := range []string{"billing/v1.3.0", "auth/v2.0.0"} {
+		_, err = origin.Tag(tagName)
+		assertTest.NoError(err, tagName)
+	}
+
+	// a second run skips the released components and fails a component with invalid version semantics
+	writeChangelog(t, filepath.Join(dir, "payments", "CHANGELOG.md"), "1.0.0", "1.1.0")
+	assertTest.Equal(subcommands.ExitFailure, create.Execute(context.Background(), nil))
+}
+
+func Test_ComponentRepoPath(t *testing.T) {
+	workingDir, err := os.Getwd()
+	assert.NoError(t, err)
+	assert.Equal(t, "services/billing", component{path: filepath.Join(workingDir, "services", "billing")}.repoPath())
+	assert.Equal(t, "services/auth", component{path: filepath.Join("services", "auth")}.repoPath())
+}
+
+func Test_CreateChangedOnly(t *testing.T) {
+	assertTest := assert.New(t)
+	originPath, clonePath, _ := initOrigin(t)
+	hash := pushFiles(t, clonePath, map[string]string{"billing/main.go": "v1", "auth/main.go": "v1"})
+	dir := t.TempDir()
+	writeChangelog(t, filepath.Join(dir, "billing", "CHANGELOG.md"), "1.3.0")
+	writeChangelog(t, filepath.Join(dir, "auth", "CHANGELOG.md"), "2.0.0")
+	configPath := filepath.Join(dir, "release.yaml")
+	err := os.WriteFile(configPath, []byte(`
+components:
+  - name: billing
+    changelog: `+filepath.Join(dir, "billing", "CHANGELOG.md")+`
+    path: billing
+  - name: auth
+    changelog: `+filepath.Join(dir, "auth", "CHANGELOG.md")+`
+    path: auth
+`), 0600)
+	assertTest.NoError(err)
+
+	create := &Create{}
+	create.origin = originPath
+	create.email = "tester@example.com"
+	create.username = "tester"
+	create.hash = hash.String()
+	create.configFile = configPath
+	create.changedOnly = true
+	assertTest.Equal(subcommands.ExitSuccess, create.Execute(context.Background(), nil))
+
+	// billing changed without a new version so fails, auth is unchanged so is skipped
+	create.hash = pushFiles(t, clonePath, map[string]string{"billing/main.go": "v2"}).String()
+	assertTest.Equal(subcommands.ExitFailure, create.Execute(context.Background(), nil))
+
+	writeChangelog(t, filepath.Join(dir, "billing", "CHANGELOG.md"), "1.4.0", "1.3.0")
+	assertTest.Equal(subcommands.ExitSuccess, create.Execute(context.Background(), nil))
+	origin, err := git.PlainOpen(originPath)
+	assertTest.NoError(err)
+	_, err = origin.Tag("billing/1.4.0")
+	assertTest.NoError(err)
+}
+
+func Test_ValidateRequireBump(t *testing.T) {
+	assertTest := assert.New(t)
+	originPath, clonePath, _ := initOrigin(t)
+	hash := pushFiles(t, clonePath, map[string]string{"src/main.go": "v1", "docs/guide.md": "v1"})
+	// the changelog is at the root of the repository so every file belongs to it
+	t.Chdir(t.TempDir())
+	writeChangelog(t, "CHANGELOG.md", "1.0.0")
+
+	create := &Create{}
+	create.origin = originPath
+	create.email = "tester@example.com"
+	create.username = "tester"
+	create.hash = hash.String()
+	create.changelog = "CHANGELOG.md"
+	assertTest.Equal(subcommands.ExitSuccess, create.Execute(context.Background(), nil))
+
+	validate := &Validate{}
+	validate.origin = originPath
+	validate.email = "tester@example.com"
+	validate.hash = pushFiles(t, clonePath, map[string]string{"docs/guide.md": "v2"}).String()
+	validate.changelog = "CHANGELOG.md"
+	assertTest.Equal(subcommands.ExitFailure, validate.Execute(context.Background(), nil))
+
+	// only source changes require a new version
+	validate.requireBump = true
+	assertTest.Equal(subcommands.ExitFailure, validate.Execute(context.Background(), nil))
+	validate.bumpPaths = "src"
+	assertTest.Equal(subcommands.ExitSuccess, validate.Execute(context.Background(), nil))
+
+	validate.hash = pushFiles(t, clonePath, map[string]string{"src/main.go": "v2"}).String()
+	assertTest.Equal(subcommands.ExitFailure, validate.Execute(context.Background(), nil))
+
+	writeChangelog(t, "CHANGELOG.md", "1.1.0", "1.0.0")
+	assertTest.Equal(subcommands.ExitSuccess, validate.Execute(context.Background(), nil))
+}
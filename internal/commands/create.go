@@ -2,30 +2,45 @@ package commands
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"github.com/google/subcommands"
-	"github.com/sanjP10/release/internal/changelog"
+	"github.com/sanjP10/release/internal/notify"
 	"github.com/sanjP10/release/internal/tag"
-	"github.com/sanjP10/release/internal/tag/providers/bitbucket"
 	"github.com/sanjP10/release/internal/tag/providers/git"
-	"github.com/sanjP10/release/internal/tag/providers/github"
-	"github.com/sanjP10/release/internal/tag/providers/gitlab"
+	"github.com/sanjP10/release/pkg/release"
 	"os"
 	"strings"
 )
 
 // Create for create sub command
 type Create struct {
-	username  string
-	password  string
-	email     string
-	changelog string
-	repo      string
-	hash      string
-	host      string
-	origin    string
-	provider  string
-	ssh       string
+	clientFlags
+	authFlags
+	componentFlags
+	notifyFlags
+	hookFlags
+	username              string
+	password              string
+	email                 string
+	changelog             string
+	repo                  string
+	hash                  string
+	host                  string
+	origin                string
+	provider              string
+	ssh                   string
+	repoPath              string
+	httpAuth              string
+	knownHosts            string
+	insecureIgnoreHostKey bool
+	taggerName            string
+	taggerEmail           string
+	tagType               string
+	signKey               string
+	signFormat            string
+	signPassword          string
+	changedOnly           bool
 }
 
 // Name of sub command
@@ -51,10 +66,26 @@ func (c *Create) SetFlags(f *flag.FlagSet) {
 	f.StringVar(&c.origin, "origin", "", "HTTPs or SSH origin of git repository, to be provided when the provider flag is not provided")
 	f.StringVar(&c.provider, "provider", "", "The Git provider, options are github, gitlab or bitbucket, when providing this flag you will be using their APIs")
 	f.StringVar(&c.ssh, "ssh", "", "SSH private key file location, please provide Username and password of the SSH file if required. Username defaults to git. This is to be used when the provider flag is not provided")
+	f.StringVar(&c.httpAuth, "http-auth", "basic", "Authentication scheme for HTTPS origins, options are basic or bearer (the password is sent as the token). This is to be used when the provider flag is not provided")
+	f.StringVar(&c.knownHosts, "known-hosts", "", "known_hosts file used to verify the SSH host key, defaults to SSH_KNOWN_HOSTS or ~/.ssh/known_hosts. This is to be used when the provider flag is not provided")
+	f.BoolVar(&c.insecureIgnoreHostKey, "insecure-ignore-host-key", false, "Skip SSH host key verification, only use this in throwaway environments")
+	f.StringVar(&c.repoPath, "repo-path", "", "Path to an existing local clone, its origin and configured credentials are used instead of fetching the repository into memory. This is to be used when the provider flag is not provided")
+	f.StringVar(&c.taggerName, "tagger-name", "", "Name recorded as the tagger, defaults to the username. This is to be used when the provider flag is not provided")
+	f.StringVar(&c.taggerEmail, "tagger-email", "", "Email recorded as the tagger, defaults to the email flag. This is to be used when the provider flag is not provided")
+	f.StringVar(&c.tagType, "tag-type", "annotated", "Kind of tag to create, options are annotated or lightweight. Lightweight tags carry no release notes or signature. This is to be used when the provider flag is not provided")
+	f.StringVar(&c.signKey, "sign-key", "", "Private key file used to sign the tag, an armored OpenPGP key or an SSH private key depending on -sign-format. This is to be used when the provider flag is not provided")
+	f.StringVar(&c.signFormat, "sign-format", "openpgp", "Format of the signing key, options are openpgp or ssh")
+	f.StringVar(&c.signPassword, "sign-password", "", "Passphrase for the signing key if it is encrypted")
+	f.BoolVar(&c.changedOnly, "changed-only", false, "Skip components whose files did not change since the tag of their top changelog version, components that changed without a new changelog version fail")
+	c.setComponentFlags(f)
+	c.setNotifyFlags(f)
+	c.setHookFlags(f)
+	c.setAuthFlags(f)
+	c.setClientFlags(f)
 }
 
 // Execute flow for create sub command
-func (c *Create) Execute(_ context.Context, _ *flag.FlagSet, _ ...interface{}) subcommands.ExitStatus {
+func (c *Create) Execute(ctx context.Context, _ *flag.FlagSet, _ ...interface{}) subcommands.ExitStatus {
 	exit := subcommands.ExitSuccess
 	errors := checkCreateFlags(c)
 	if len(errors) > 0 {
@@ -65,84 +96,236 @@ func (c *Create) Execute(_ context.Context, _ *flag.FlagSet, _ ...interface{}) s
 			panic("Cannot write to stderr")
 		}
 	} else {
-		changelogFile, err := changelog.ReadChangelogAsString(c.changelog)
+		ctx, cancel := c.withDeadline(ctx)
+		defer cancel()
+		components, err := c.components(c.changelog)
+		var notifiers []notify.Notifier
+		if err == nil {
+			notifiers, err = c.releaseNotifiers()
+		}
 		if err != nil {
 			exit = subcommands.ExitUsageError
-			_, err := os.Stderr.WriteString("Unable to read changelog\n")
+			_, err := os.Stderr.WriteString(err.Error() + "\n")
 			if err != nil {
 				panic("Cannot write to stderr")
 			}
+		} else if !monorepo(components) {
+			exit = c.createComponent(ctx, components[0], false, notifiers).exit
 		} else {
-			changelogObj := changelog.Properties{}
-			changelogObj.GetVersions(changelogFile)
-			validSemantics := changelogObj.ValidateVersionSemantics()
-			if !validSemantics {
-				exit = subcommands.ExitFailure
-				_, err := os.Stderr.WriteString("Invalid version semantics\n")
-				if err != nil {
-					panic("Cannot write to stderr")
-				}
-			} else {
-				changelogObj.RetrieveChanges(changelogFile)
-				desiredTag := changelogObj.ConvertToDesiredTag()
-				success, err := createProviderTag(c, desiredTag, changelogObj)
-				if err != nil {
-					_, err := os.Stderr.WriteString("Error creating tag with repo " + c.origin + " " + err.Error() + "\n")
-					if err != nil {
-						panic("Cannot write to stderr")
-					}
-					exit = subcommands.ExitFailure
-				} else if !success {
-					_, err := os.Stderr.WriteString("Error creating Tag " + strings.TrimSpace(desiredTag) + "\n")
-					if err != nil {
-						panic("Cannot write to stderr")
-					}
-					exit = subcommands.ExitFailure
-				} else {
-					_, err := os.Stdout.WriteString(strings.TrimSpace(desiredTag) + "\n")
-					if err != nil {
-						panic("Cannot write to stderr")
-					}
+			summary := releaseSummary{}
+			for _, current := range components {
+				summary.add(c.createComponent(ctx, current, true, notifiers))
+				if ctx.Err() != nil {
+					break
 				}
 			}
+			summary.write()
+			if summary.failed() {
+				exit = subcommands.ExitFailure
+			}
 		}
 	}
 	return exit
 }
 
+// releaseNotifiers are the targets of the notify flags and config file. They have their own client, the TLS options
+// of the provider are not applied to third party endpoints
+func (c *Create) releaseNotifiers() ([]notify.Notifier, error) {
+	client, err := tag.NewHTTPClient(tag.ClientOptions{Timeout: c.timeout})
+	if err != nil {
+		return nil, errors.New("unable to set up notifications, " + err.Error())
+	}
+	return c.notifiers(c.loaded.Notifications, client), nil
+}
+
+// createComponent creates the tag of the top version of the component changelog, in a monorepo an existing tag at
+// the hash is skipped. With -changed-only an existing tag at another commit is skipped when the component is unchanged.
+// Hooks run before validating and around creating the tag, a failed pre-validate or pre-create hook aborts the release.
+// The pre-create and post-create hooks are skipped when the tag already exists at the hash or could not be checked
+func (c *Create) createComponent(ctx context.Context, current component, monorepo bool, notifiers []notify.Notifier) componentResult {
+	result := componentResult{component: current, outcome: outcomeFailed, exit: subcommands.ExitFailure}
+	changelogObj, err := release.ReadChangelog(current.changelog)
+	if err != nil {
+		result.exit = subcommands.ExitUsageError
+		result.reason = "unable to read changelog"
+		_, err := os.Stderr.WriteString("Unable to read changelog " + current.changelog + "\n")
+		if err != nil {
+			panic("Cannot write to stderr")
+		}
+		return result
+	}
+	latest, ok := changelogObj.Latest()
+	if !ok {
+		result.reason = "no version in changelog"
+		_, err := os.Stderr.WriteString("No version found in changelog " + current.changelog + "\n")
+		if err != nil {
+			panic("Cannot write to stderr")
+		}
+		return result
+	}
+	desiredTag := current.tag(latest.Version)
+	result.tag = desiredTag
+	var env []string
+	if c.hasHooks(c.loaded.Hooks) {
+		var cleanup func()
+		env, cleanup, err = hookEnv(current, desiredTag, latest.Version, c.hash, c.provider, latest.Notes)
+		defer cleanup()
+		if err != nil {
+			result.reason = "unable to write release notes for hooks"
+			_, err := os.Stderr.WriteString("Unable to write release notes for hooks " + err.Error() + "\n")
+			if err != nil {
+				panic("Cannot write to stderr")
+			}
+			return result
+		}
+	}
+	if !c.runHookStage(ctx, hookPreValidate, env) {
+		result.reason = hookPreValidate + " hook failed"
+		return result
+	}
+	err = changelogObj.Validate()
+	if err != nil {
+		result.reason = "invalid version semantics"
+		_, err := os.Stderr.WriteString("Invalid version semantics, " + err.Error() + "\n")
+		if err != nil {
+			panic("Cannot write to stderr")
+		}
+		return result
+	}
+	client, err := c.tagClient()
+	releaseTag := release.Tag{Name: desiredTag, Hash: c.hash, Notes: latest.Notes}
+	existed, conflict, unknown := false, false, false
+	if err == nil {
+		if monorepo || c.changedOnly || len(notifiers) > 0 || len(c.hooks(hookPreCreate, c.loaded.Hooks)) > 0 || len(c.hooks(hookPostCreate, c.loaded.Hooks)) > 0 {
+			state, validateErr := client.ValidateTag(ctx, releaseTag)
+			if monorepo && validateErr == nil && state == release.TagAtHash {
+				result.outcome, result.exit, result.reason = outcomeSkipped, subcommands.ExitSuccess, "already released"
+				return result
+			}
+			// an existing tag at the hash was announced when it was created
+			existed = validateErr == nil && state == release.TagAtHash
+			conflict = validateErr == nil && state == release.TagAtOtherCommit
+			unknown = validateErr != nil
+			if c.changedOnly && conflict {
+				return unchangedSinceTag(ctx, client, releaseTag, result)
+			}
+		}
+		// nothing is prepared for a tag that was already released, or that fails to be created because it is at another
+		// commit or could not be checked
+		if !existed && !conflict && !unknown && !c.runHookStage(ctx, hookPreCreate, env) {
+			result.reason = hookPreCreate + " hook failed"
+			return result
+		}
+	}
+	if err != nil {
+		result.reason = err.Error()
+		_, err := os.Stderr.WriteString("Error creating tag with repo " + c.repoName() + " " + err.Error() + "\n")
+		if err != nil {
+			panic("Cannot write to stderr")
+		}
+		return result
+	}
+	err = client.CreateTag(ctx, releaseTag)
+	if reportInterrupted(ctx, "creating tag "+desiredTag) {
+		result.reason = "interrupted"
+	} else if err != nil {
+		result.reason = "error creating tag"
+		_, err := os.Stderr.WriteString("Error creating Tag " + desiredTag + ", " + err.Error() + "\n")
+		if err != nil {
+			panic("Cannot write to stderr")
+		}
+	} else {
+		result = c.released(ctx, result, client, releaseTag, env, notifiers, existed)
+	}
+	return result
+}
+
+// released reports the created tag, when it is new the post-create hooks are run and the tag is announced. Failed
+// notifications only fail the component when they are strict
+func (c *Create) released(ctx context.Context, result componentResult, client release.Client, releaseTag release.Tag, env []string, notifiers []notify.Notifier, existed bool) componentResult {
+	result.outcome, result.exit = outcomeReleased, subcommands.ExitSuccess
+	_, err := os.Stdout.WriteString(result.tag + "\n")
+	if err != nil {
+		panic("Cannot write to stderr")
+	}
+	if !existed && !c.runHookStage(ctx, hookPostCreate, env) {
+		result.outcome, result.exit, result.reason = outcomeFailed, subcommands.ExitFailure, "released, "+hookPostCreate+" hook failed"
+	}
+	if len(notifiers) > 0 && !existed && !notifyRelease(ctx, notifiers, client, c.repoName(), releaseTag) &&
+		(c.notifyStrict || c.loaded.Notifications.Strict) {
+		result.outcome, result.exit, result.reason = outcomeFailed, subcommands.ExitFailure, "released, notifications failed"
+	}
+	return result
+}
+
+// runHookStage runs the hooks of the stage, a failure is reported
+func (c *Create) runHookStage(ctx context.Context, stage string, env []string) bool {
+	err := c.runHooks(ctx, stage, c.loaded.Hooks, env)
+	if err != nil {
+		_, err := os.Stderr.WriteString("Error running hooks " + err.Error() + "\n")
+		if err != nil {
+			panic("Cannot write to stderr")
+		}
+		return false
+	}
+	return true
+}
+
+// repoName names the repository in notifications
+func (c *Create) repoName() string {
+	if len(c.provider) > 0 {
+		return c.repo
+	}
+	return displayOrigin(c.origin, c.repoPath)
+}
+
 func checkCreateFlags(c *Create) []string {
 	var errors []string
 	if len(c.provider) == 0 {
-		// Use regular git. Check for origin, username/ssh and email
-		if len(c.origin) == 0 {
+		// Use regular git. Check for origin, username/ssh and email, a local clone provides its own origin and credentials
+		if len(c.origin) == 0 && len(c.repoPath) == 0 {
 			errors = append(errors, "-origin required")
 		}
-		if len(c.username) == 0 && len(c.ssh) == 0 {
+		// without explicit credentials ssh origins use ssh-agent and https origins use git credential helpers or netrc
+		implicitAuth := len(c.origin) > 0 || len(c.repoPath) > 0
+		if len(c.username) == 0 && len(c.ssh) == 0 && !implicitAuth {
 			errors = append(errors, "-username or -ssh required, for CodeCommit or GCP Source repositories both are required")
 		}
-		if len(c.password) == 0 && len(c.ssh) == 0 {
+		if len(c.password) == 0 && len(c.ssh) == 0 && !implicitAuth {
 			errors = append(errors, "-password required")
 		}
-		if len(c.email) == 0 {
+		if len(c.email) == 0 && len(c.taggerEmail) == 0 && len(c.repoPath) == 0 {
 			errors = append(errors, "-email required")
 		}
 	} else if ValidProvider(c.provider) {
-		// for valid providers check for username and repo
-		if len(c.username) == 0 && strings.ToLower(c.provider) != "gitlab" {
-			errors = append(errors, "-username required")
-		}
-		if len(c.password) == 0 {
-			errors = append(errors, "-password required")
-		}
+		// for valid providers check for the credentials of the authentication type and repo
+		errors = append(errors, c.checkAuthFlags(c.provider, c.username, c.password)...)
 		if len(c.repo) == 0 {
 			errors = append(errors, "-repo required")
 		}
+		errors = append(errors, checkRepoFlag(c.provider, c.repo, c.host)...)
 	} else {
 		// valid provider values
 		errors = append(errors, "-provider valid values are "+strings.Join(providers[:], ", "))
 	}
+	if len(c.signKey) > 0 && len(c.provider) > 0 {
+		errors = append(errors, "-sign-key is only supported when the provider flag is not supplied")
+	}
+	if !git.ValidTagType(c.tagType) {
+		errors = append(errors, "-tag-type valid values are "+git.TagTypeAnnotated+", "+git.TagTypeLightweight)
+	} else if len(c.signKey) > 0 && strings.ToLower(c.tagType) == git.TagTypeLightweight {
+		errors = append(errors, "-sign-key cannot be used with lightweight tags")
+	}
+	if !git.ValidSignFormat(c.signFormat) {
+		errors = append(errors, "-sign-format valid values are "+git.SignFormatOpenPGP+", "+git.SignFormatSSH)
+	}
+	if !git.ValidHTTPAuth(c.httpAuth) {
+		errors = append(errors, "-http-auth valid values are "+git.HTTPAuthBasic+", "+git.HTTPAuthBearer)
+	}
+	errors = append(errors, c.checkClientFlags()...)
+	errors = append(errors, c.checkNotifyFlags()...)
 	// changelog and hash are mandatory
-	if len(c.changelog) == 0 {
+	if len(c.changelog) == 0 && len(c.configFile) == 0 {
 		errors = append(errors, "-changelog required")
 	}
 	if len(c.hash) == 0 {
@@ -151,31 +334,26 @@ func checkCreateFlags(c *Create) []string {
 	return errors
 }
 
-func createProviderTag(c *Create, desiredTag string, changelogObj changelog.Properties) (bool, error) {
-	success := false
-	properties := tag.RepoProperties{
-		Password: c.password,
-		Tag:      strings.TrimSpace(desiredTag),
-		Hash:     c.hash,
-		Body:     changelogObj.Changes,
-	}
-	switch strings.ToLower(c.provider) {
-	case "github":
-		provider := github.Properties{Username: c.username, Repo: c.repo, Host: c.host, RepoProperties: properties}
-		success = provider.CreateTag()
-	case "gitlab":
-		provider := gitlab.Properties{Repo: c.repo, Host: c.host, RepoProperties: properties}
-		success = provider.CreateTag()
-	case "bitbucket":
-		provider := bitbucket.Properties{Username: c.username, Repo: c.repo, Host: c.host, RepoProperties: properties}
-		success = provider.CreateTag()
-	default:
-		provider := git.Properties{Username: c.username, Email: c.email, Origin: c.origin, SSH: c.ssh, RepoProperties: properties}
-		err := provider.InitializeRepository()
-		if err != nil {
-			return false, err
-		}
-		success = provider.CreateTag()
+// tagClient sets up the provider or git repository the tag is created with
+func (c *Create) tagClient() (release.Client, error) {
+	if len(c.provider) > 0 {
+		return c.releaseClient(&c.clientFlags, c.provider, c.username, c.password, c.repo, c.host)
 	}
-	return success, nil
+	return release.NewGit(release.GitOptions{
+		Origin:                c.origin,
+		RepoPath:              c.repoPath,
+		Username:              c.username,
+		Password:              c.password,
+		BearerToken:           strings.ToLower(c.httpAuth) == git.HTTPAuthBearer,
+		SSHKey:                c.ssh,
+		KnownHosts:            c.knownHosts,
+		InsecureIgnoreHostKey: c.insecureIgnoreHostKey,
+		Email:                 c.email,
+		TaggerName:            c.taggerName,
+		TaggerEmail:           c.taggerEmail,
+		Lightweight:           strings.ToLower(c.tagType) == git.TagTypeLightweight,
+		SignKey:               c.signKey,
+		SignFormat:            c.signFormat,
+		SignPassword:          c.signPassword,
+	})
 }
@@ -1,7 +1,12 @@
 package commands
 
 import (
+	"context"
+	"github.com/google/subcommands"
 	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
 	"testing"
 )
 
@@ -203,3 +208,127 @@ func Test_CreateCheckFlag_Gitlab(t *testing.T) {
 	errors := checkCreateFlags(create)
 	assertTest.Empty(errors)
 }
+
+func Test_CreateCheckFlag_Signing(t *testing.T) {
+	create := &Create{}
+	create.password = "token"
+	create.provider = "gitlab"
+	create.repo = "repo"
+	create.hash = "hash"
+	create.changelog = "file"
+	create.signKey = "key.asc"
+	create.signFormat = "x509"
+	assertTest := assert.New(t)
+	expected := []string{
+		"-sign-key is only supported when the provider flag is not supplied",
+		"-sign-format valid values are openpgp, ssh"}
+	assertTest.Equal(expected, checkCreateFlags(create))
+}
+
+func Test_CreateCheckFlag_RepoPath(t *testing.T) {
+	create := &Create{}
+	create.repoPath = "."
+	create.hash = "hash"
+	create.changelog = "file"
+	assertTest := assert.New(t)
+	assertTest.Empty(checkCreateFlags(create))
+}
+
+func Test_CreateCheckFlag_TagType(t *testing.T) {
+	create := &Create{}
+	create.repoPath = "."
+	create.hash = "hash"
+	create.changelog = "file"
+	create.tagType = "signed"
+	assertTest := assert.New(t)
+	assertTest.Equal([]string{"-tag-type valid values are annotated, lightweight"}, checkCreateFlags(create))
+
+	create.tagType = "lightweight"
+	create.signKey = "key.asc"
+	assertTest.Equal([]string{"-sign-key cannot be used with lightweight tags"}, checkCreateFlags(create))
+}
+
+func Test_CreateCheckFlag_HTTPAuth(t *testing.T) {
+	create := &Create{}
+	create.origin = "https://an-origin.com/repo.git"
+	create.email = "an-email@abc.com"
+	create.hash = "hash"
+	create.changelog = "file"
+	assertTest := assert.New(t)
+	// credentials can come from git credential helpers or netrc
+	assertTest.Empty(checkCreateFlags(create))
+
+	create.httpAuth = "digest"
+	assertTest.Equal([]string{"-http-auth valid values are basic, bearer"}, checkCreateFlags(create))
+}
+
+func Test_CreateCheckFlag_GithubAuthType(t *testing.T) {
+	create := &Create{}
+	create.provider = "github"
+	create.repo = "owner/repo"
+	create.hash = "hash"
+	create.changelog = "file"
+	assertTest := assert.New(t)
+
+	create.authType = "bearer"
+	assertTest.Equal([]string{"-password required"}, checkCreateFlags(create))
+	create.password = "token"
+	assertTest.Empty(checkCreateFlags(create))
+
+	create.authType = "app"
+	create.password = ""
+	assertTest.Equal([]string{"-app-id required", "-app-key required"}, checkCreateFlags(create))
+	create.appID = "12345"
+	create.appKey = "app.pem"
+	assertTest.Empty(checkCreateFlags(create))
+
+	create.authType = "oauth"
+	assertTest.Equal([]string{"-auth-type valid values for github are basic, bearer, app"}, checkCreateFlags(create))
+}
+
+func Test_CreateCheckFlag_BitbucketServer(t *testing.T) {
+	create := &Create{}
+	create.provider = "bitbucket"
+	create.host = "https://bitbucket.example.com"
+	create.password = "token"
+	create.authType = "bearer"
+	create.hash = "hash"
+	create.changelog = "file"
+	assertTest := assert.New(t)
+
+	create.repo = "repo"
+	assertTest.Equal([]string{`-repo "repo" must be in the format project/repo, or ~user/repo for a personal repo`}, checkCreateFlags(create))
+	create.repo = "~user/repo"
+	assertTest.Empty(checkCreateFlags(create))
+
+	create.authType = "app"
+	assertTest.Equal([]string{"-auth-type valid values for bitbucket are basic, bearer"}, checkCreateFlags(create))
+}
+
+func TestCreate_ReportsProviderError(t *testing.T) {
+	assertTest := assert.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		_, _ = w.Write([]byte(`{"message": "Validation Failed", "errors": [{"code": "invalid"}]}`))
+	}))
+	defer server.Close()
+	changelogPath := filepath.Join(t.TempDir(), "CHANGELOG.md")
+	writeChangelog(t, changelogPath, "1.0.0")
+
+	create := &Create{}
+	create.provider = "github"
+	create.username = "tester"
+	create.password = "token"
+	create.repo = "owner/repo"
+	create.host = server.URL
+	create.hash = "hash"
+	create.changelog = changelogPath
+	stderr := captureStderr(t, func() {
+		assertTest.Equal(subcommands.ExitFailure, create.Execute(context.Background(), nil))
+	})
+	assertTest.Contains(stderr, "Error creating Tag 1.0.0, unable to create tag, status 422: invalid\n")
+}
@@ -0,0 +1,107 @@
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"github.com/sanjP10/release/internal/config"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Stages of create that hooks run at
+const (
+	hookPreValidate = "pre-validate"
+	hookPreCreate   = "pre-create"
+	hookPostCreate  = "post-create"
+)
+
+// hookFlags are shell commands run around validating and creating each tag, adding to the hooks of the config file
+type hookFlags struct {
+	preValidateHooks stringList
+	preCreateHooks   stringList
+	postCreateHooks  stringList
+}
+
+func (hf *hookFlags) setHookFlags(f *flag.FlagSet) {
+	f.Var(&hf.preValidateHooks, "pre-validate-hook", "Shell command run before the changelog and tag are validated, a failure aborts the release. Can be given several times")
+	f.Var(&hf.preCreateHooks, "pre-create-hook", "Shell command run before the tag is created, a failure aborts the release. Can be given several times")
+	f.Var(&hf.postCreateHooks, "post-create-hook", "Shell command run after the tag is created, a failure fails the command. Can be given several times")
+}
+
+// hooks of a stage, those of the flags run before those of the config file
+func (hf *hookFlags) hooks(stage string, configured config.Hooks) []string {
+	switch stage {
+	case hookPreValidate:
+		return append(append([]string{}, hf.preValidateHooks...), configured.PreValidate...)
+	case hookPreCreate:
+		return append(append([]string{}, hf.preCreateHooks...), configured.PreCreate...)
+	default:
+		return append(append([]string{}, hf.postCreateHooks...), configured.PostCreate...)
+	}
+}
+
+// hasHooks reports whether any stage has hooks
+func (hf *hookFlags) hasHooks(configured config.Hooks) bool {
+	for _, stage := range []string{hookPreValidate, hookPreCreate, hookPostCreate} {
+		if len(hf.hooks(stage, configured)) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// runHooks runs the hooks of the stage in order with the release in their environment, stopping at the first failure.
+// Their output is written to stderr as stdout holds the created tags
+func (hf *hookFlags) runHooks(ctx context.Context, stage string, configured config.Hooks, env []string) error {
+	for _, hook := range hf.hooks(stage, configured) {
+		cmd := hookCommand(ctx, hook)
+		cmd.Env = append(append(os.Environ(), env...), "RELEASE_HOOK="+stage)
+		cmd.Stdout = os.Stderr
+		cmd.Stderr = os.Stderr
+		err := cmd.Run()
+		if err != nil {
+			return fmt.Errorf("%s hook %q failed: %w", stage, hook, err)
+		}
+	}
+	return nil
+}
+
+// hookCommand runs the hook with the shell of the platform
+func hookCommand(ctx context.Context, hook string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", hook)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", hook)
+}
+
+// hookEnv describes the release to hooks, the notes are written to a file removed by the returned cleanup
+func hookEnv(current component, desiredTag string, version string, hash string, provider string, notes string) ([]string, func(), error) {
+	notesFile, err := os.CreateTemp("", "release-notes-*.md")
+	if err != nil {
+		return nil, func() {}, err
+	}
+	cleanup := func() { _ = os.Remove(notesFile.Name()) }
+	_, err = notesFile.WriteString(notes)
+	if closeErr := notesFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		cleanup()
+		return nil, func() {}, err
+	}
+	if provider == "" {
+		provider = "git"
+	}
+	return []string{
+		"RELEASE_TAG=" + desiredTag,
+		"RELEASE_VERSION=" + strings.TrimSpace(version),
+		"RELEASE_HASH=" + hash,
+		"RELEASE_NOTES_FILE=" + notesFile.Name(),
+		"RELEASE_PROVIDER=" + strings.ToLower(provider),
+		"RELEASE_COMPONENT=" + current.name,
+		"RELEASE_CHANGELOG=" + current.changelog,
+	}, cleanup, nil
+}
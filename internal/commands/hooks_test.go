@@ -0,0 +1,152 @@
+package commands
+
+import (
+	"context"
+	"github.com/go-git/go-git/v5"
+	"github.com/google/subcommands"
+	"github.com/sanjP10/release/internal/config"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func Test_CreateHooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hooks in this test are written for sh")
+	}
+	assertTest := assert.New(t)
+	originPath, _, hash := initOrigin(t)
+	dir := t.TempDir()
+	changelogPath := filepath.Join(dir, "CHANGELOG.md")
+	writeChangelog(t, changelogPath, "1.1.0", "1.0.0")
+
+	create := &Create{}
+	create.origin = originPath
+	create.email = "tester@example.com"
+	create.username = "tester"
+	create.hash = hash.String()
+	create.changelog = changelogPath
+	create.preValidateHooks = stringList{`echo "$RELEASE_HOOK $RELEASE_TAG $RELEASE_VERSION $RELEASE_PROVIDER $RELEASE_HASH" > ` + filepath.Join(dir, "pre-validate")}
+	create.preCreateHooks = stringList{"exit 3"}
+	create.postCreateHooks = stringList{`cp "$RELEASE_NOTES_FILE" ` + filepath.Join(dir, "notes")}
+
+	// a failed pre-create hook aborts the release
+	assertTest.Equal(subcommands.ExitFailure, create.Execute(context.Background(), nil))
+	preValidate, err := os.ReadFile(filepath.Join(dir, "pre-validate"))
+	assertTest.NoError(err)
+	assertTest.Equal("pre-validate 1.1.0 1.1.0 git "+hash.String()+"\n", string(preValidate))
+	origin, err := git.PlainOpen(originPath)
+	assertTest.NoError(err)
+	_, err = origin.Tag("1.1.0")
+	assertTest.Error(err)
+	assertTest.NoFileExists(filepath.Join(dir, "notes"))
+
+	create.preCreateHooks = stringList{"true"}
+	assertTest.Equal(subcommands.ExitSuccess, create.Execute(context.Background(), nil))
+	_, err = origin.Tag("1.1.0")
+	assertTest.NoError(err)
+	notes, err := os.ReadFile(filepath.Join(dir, "notes"))
+	assertTest.NoError(err)
+	assertTest.Equal("### Added\n* Changes in 1.1.0", string(notes))
+
+	// a failed post-create hook fails the command after the tag is created
+	create.postCreateHooks = stringList{"false"}
+	writeChangelog(t, changelogPath, "1.2.0", "1.1.0")
+	assertTest.Equal(subcommands.ExitFailure, create.Execute(context.Background(), nil))
+	_, err = origin.Tag("1.2.0")
+	assertTest.NoError(err)
+}
+
+func Test_CreateHooksSkippedForExistingTag(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hooks in this test are written for sh")
+	}
+	assertTest := assert.New(t)
+	originPath, _, hash := initOrigin(t)
+	dir := t.TempDir()
+	changelogPath := filepath.Join(dir, "CHANGELOG.md")
+	writeChangelog(t, changelogPath, "1.0.0")
+
+	create := &Create{}
+	create.origin = originPath
+	create.email = "tester@example.com"
+	create.username = "tester"
+	create.hash = hash.String()
+	create.changelog = changelogPath
+	assertTest.Equal(subcommands.ExitSuccess, create.Execute(context.Background(), nil))
+
+	// the tag was released by the first run, so nothing is prepared or published again
+	create.preCreateHooks = stringList{"touch " + filepath.Join(dir, "pre-create")}
+	create.postCreateHooks = stringList{"touch " + filepath.Join(dir, "post-create")}
+	assertTest.Equal(subcommands.ExitSuccess, create.Execute(context.Background(), nil))
+	assertTest.NoFileExists(filepath.Join(dir, "pre-create"))
+	assertTest.NoFileExists(filepath.Join(dir, "post-create"))
+}
+
+func Test_CreateHooksSkippedForUnknownTag(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hooks in this test are written for sh")
+	}
+	assertTest := assert.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	dir := t.TempDir()
+	changelogPath := filepath.Join(dir, "CHANGELOG.md")
+	writeChangelog(t, changelogPath, "1.0.0")
+
+	create := &Create{}
+	create.provider = "github"
+	create.username = "tester"
+	create.password = "token"
+	create.repo = "owner/repo"
+	create.host = server.URL
+	create.hash = "hash"
+	create.changelog = changelogPath
+	create.preCreateHooks = stringList{"touch " + filepath.Join(dir, "pre-create")}
+	assertTest.Equal(subcommands.ExitFailure, create.Execute(context.Background(), nil))
+	assertTest.NoFileExists(filepath.Join(dir, "pre-create"))
+}
+
+func Test_CreatePreValidateHookFails(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hooks in this test are written for sh")
+	}
+	changelogPath := filepath.Join(t.TempDir(), "CHANGELOG.md")
+	writeChangelog(t, changelogPath, "1.0.0")
+	create := &Create{}
+	create.hash = "hash"
+	create.loaded.Hooks = config.Hooks{PreValidate: []string{"exit 1"}}
+	result := create.createComponent(context.Background(), component{changelog: changelogPath, tagTemplate: versionPlaceholder}, false, nil)
+	assert.Equal(t, outcomeFailed, result.outcome)
+	assert.Equal(t, "pre-validate hook failed", result.reason)
+}
+
+func Test_HookFlagsOrder(t *testing.T) {
+	flags := hookFlags{preCreateHooks: stringList{"make build"}}
+	configured := config.Hooks{PreCreate: []string{"make package"}}
+	assert.Equal(t, []string{"make build", "make package"}, flags.hooks(hookPreCreate, configured))
+	assert.True(t, flags.hasHooks(config.Hooks{}))
+	assert.False(t, (&hookFlags{}).hasHooks(config.Hooks{}))
+}
+
+func Test_CreateWithoutVersionSkipsHooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hooks in this test are written for sh")
+	}
+	dir := t.TempDir()
+	changelogPath := filepath.Join(dir, "CHANGELOG.md")
+	writeChangelog(t, changelogPath)
+	create := &Create{}
+	create.hash = "hash"
+	create.preValidateHooks = stringList{"touch " + filepath.Join(dir, "pre-validate")}
+	result := create.createComponent(context.Background(), component{changelog: changelogPath, tagTemplate: versionPlaceholder}, false, nil)
+	assert.Equal(t, outcomeFailed, result.outcome)
+	assert.Equal(t, "no version in changelog", result.reason)
+	assert.NoFileExists(t, filepath.Join(dir, "pre-validate"))
+}
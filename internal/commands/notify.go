@@ -0,0 +1,101 @@
+package commands
+
+import (
+	"context"
+	"flag"
+	"github.com/sanjP10/release/internal/config"
+	"github.com/sanjP10/release/internal/notify"
+	"github.com/sanjP10/release/pkg/release"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// stringList collects the values of a flag given several times
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ",") }
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// notifyFlags select the targets created releases are announced to, adding to the notifications of the config file
+type notifyFlags struct {
+	notifyWebhooks stringList
+	notifySlack    stringList
+	notifyTeams    stringList
+	notifyStrict   bool
+}
+
+func (nf *notifyFlags) setNotifyFlags(f *flag.FlagSet) {
+	f.Var(&nf.notifyWebhooks, "notify-webhook", "URL the created release is posted to as JSON, can be given several times")
+	f.Var(&nf.notifySlack, "notify-slack", "Slack incoming webhook URL the created release is announced to, can be given several times")
+	f.Var(&nf.notifyTeams, "notify-teams", "Microsoft Teams connector URL the created release is announced to, can be given several times")
+	f.BoolVar(&nf.notifyStrict, "notify-strict", false, "Fail when a notification cannot be sent, failed notifications are only reported by default")
+}
+
+func (nf *notifyFlags) checkNotifyFlags() []string {
+	var errors []string
+	names := []string{"-notify-webhook", "-notify-slack", "-notify-teams"}
+	for i, urls := range []stringList{nf.notifyWebhooks, nf.notifySlack, nf.notifyTeams} {
+		for _, notifyURL := range urls {
+			if !config.ValidURL(notifyURL) {
+				errors = append(errors, names[i]+" "+notifyURL+" must be an http or https URL")
+			}
+		}
+	}
+	return errors
+}
+
+// notifiers are the targets of the flags followed by those of the config file
+func (nf *notifyFlags) notifiers(notifications config.Notifications, client *http.Client) []notify.Notifier {
+	var notifiers []notify.Notifier
+	for _, webhook := range append(append([]string{}, nf.notifyWebhooks...), notifications.Webhooks...) {
+		notifiers = append(notifiers, notify.Webhook{URL: webhook, Client: client})
+	}
+	for _, slack := range append(append([]string{}, nf.notifySlack...), notifications.Slack...) {
+		notifiers = append(notifiers, notify.Slack{URL: slack, Client: client})
+	}
+	for _, teams := range append(append([]string{}, nf.notifyTeams...), notifications.Teams...) {
+		notifiers = append(notifiers, notify.Teams{URL: teams, Client: client})
+	}
+	return notifiers
+}
+
+// notifyRelease announces the created tag, failures are reported and returned as false
+func notifyRelease(ctx context.Context, notifiers []notify.Notifier, client release.Client, repo string, releaseTag release.Tag) bool {
+	notification := notify.Release{Tag: releaseTag.Name, Repo: repo, Notes: releaseTag.Notes}
+	if linker, ok := client.(release.ReleaseLinker); ok {
+		notification.URL = linker.ReleaseURL(releaseTag)
+	}
+	errors := notify.NotifyAll(ctx, notifiers, notification)
+	for _, err := range errors {
+		_, err := os.Stderr.WriteString("Error notifying release " + releaseTag.Name + " to " + err.Error() + "\n")
+		if err != nil {
+			panic("Cannot write to stderr")
+		}
+	}
+	return len(errors) == 0
+}
+
+// displayOrigin removes credentials from an origin so it can be shown in notifications, a local clone is named after
+// its directory
+func displayOrigin(origin string, repoPath string) string {
+	if origin == "" {
+		absolute, err := filepath.Abs(repoPath)
+		if err != nil {
+			return repoPath
+		}
+		return filepath.Base(absolute)
+	}
+	parsed, err := url.Parse(origin)
+	if err != nil || parsed.User == nil {
+		return origin
+	}
+	parsed.User = nil
+	return parsed.String()
+}
@@ -0,0 +1,225 @@
+package commands
+
+import (
+	"context"
+	"flag"
+	"github.com/google/subcommands"
+	"github.com/sanjP10/release/pkg/release"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// previewMarker identifies the preview comment so it is updated in place on later runs
+const previewMarker = "<!-- release-preview -->"
+
+// Preview for preview sub command
+type Preview struct {
+	clientFlags
+	authFlags
+	componentFlags
+	username    string
+	password    string
+	changelog   string
+	repo        string
+	hash        string
+	host        string
+	provider    string
+	pullRequest int
+}
+
+// previewResult is the tag and validation of a component shown in the preview
+type previewResult struct {
+	component component
+	tag       string
+	notes     string
+	status    string
+	valid     bool
+}
+
+// Name of sub command
+func (*Preview) Name() string { return "preview" }
+
+// Synopsis of sub command
+func (*Preview) Synopsis() string { return "Comments the release preview on a pull request." }
+
+// Usage of sub command
+func (*Preview) Usage() string {
+	return "Posts or updates a comment on a pull request with the tag that will be created, its release notes and the validation results.\n"
+}
+
+// SetFlags required for preview sub command
+func (p *Preview) SetFlags(f *flag.FlagSet) {
+	f.StringVar(&p.username, "username", "", "Username (gitlab provider does not require this field)")
+	f.StringVar(&p.password, "password", "", "Password or API token (gitlab provider requires an api token)")
+	f.StringVar(&p.repo, "repo", "", "The repo name, this should include the organisation or owner")
+	f.StringVar(&p.changelog, "changelog", "", "Location of changelog markdown file")
+	f.StringVar(&p.hash, "hash", "", "The Full commit hash the tag would be created for, the head of the pull request")
+	f.StringVar(&p.host, "host", "", "The host for self hosted instances of the allowed providers")
+	f.StringVar(&p.provider, "provider", "", "The Git provider hosting the pull request, options are github, gitlab or bitbucket")
+	f.IntVar(&p.pullRequest, "pr", 0, "The number of the GitHub pull request, GitLab merge request or Bitbucket pull request to comment on")
+	p.setComponentFlags(f)
+	p.setAuthFlags(f)
+	p.setClientFlags(f)
+}
+
+// Execute flow for preview sub command
+func (p *Preview) Execute(ctx context.Context, _ *flag.FlagSet, _ ...interface{}) subcommands.ExitStatus {
+	exit := subcommands.ExitSuccess
+	errors := checkPreviewFlags(p)
+	if len(errors) > 0 {
+		errors = append(errors, "\n")
+		exit = subcommands.ExitUsageError
+		_, err := os.Stderr.WriteString("missing flags for preview:\n" + strings.Join(errors, "\n"))
+		if err != nil {
+			panic("Cannot write to stderr")
+		}
+		return exit
+	}
+	ctx, cancel := p.withDeadline(ctx)
+	defer cancel()
+	components, err := p.components(p.changelog)
+	if err != nil {
+		_, err := os.Stderr.WriteString(err.Error() + "\n")
+		if err != nil {
+			panic("Cannot write to stderr")
+		}
+		return subcommands.ExitUsageError
+	}
+	var results []previewResult
+	for _, current := range components {
+		result := p.previewComponent(ctx, current)
+		if !result.valid {
+			exit = subcommands.ExitFailure
+		}
+		results = append(results, result)
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	body := previewComment(p.hash, results)
+	_, err = os.Stdout.WriteString(body)
+	if err != nil {
+		panic("Cannot write to stdout")
+	}
+	if reportInterrupted(ctx, "validating tags") {
+		return subcommands.ExitFailure
+	}
+	provider, err := newPreviewProvider(p)
+	if err == nil {
+		err = provider.UpsertComment(ctx, p.pullRequest, previewMarker, body)
+	}
+	if reportInterrupted(ctx, "commenting on pull request "+strconv.Itoa(p.pullRequest)) {
+		exit = subcommands.ExitFailure
+	} else if err != nil {
+		exit = subcommands.ExitFailure
+		_, err := os.Stderr.WriteString("Error commenting on pull request " + strconv.Itoa(p.pullRequest) + " of " + p.repo + " " + err.Error() + "\n")
+		if err != nil {
+			panic("Cannot write to stderr")
+		}
+	}
+	return exit
+}
+
+// previewComponent reads the tag and notes of the top version of the component changelog and validates the tag
+func (p *Preview) previewComponent(ctx context.Context, current component) previewResult {
+	result := previewResult{component: current}
+	changelogObj, err := release.ReadChangelog(current.changelog)
+	if err != nil {
+		result.status = "Unable to read changelog " + current.changelog
+		return result
+	}
+	latest, ok := changelogObj.Latest()
+	if !ok {
+		result.status = "No version found in changelog " + current.changelog
+		return result
+	}
+	result.tag = current.tag(latest.Version)
+	result.notes = latest.Notes
+	err = changelogObj.Validate()
+	if err != nil {
+		result.status = "Invalid version semantics, " + err.Error()
+		return result
+	}
+	client, err := p.releaseClient(&p.clientFlags, p.provider, p.username, p.password, p.repo, p.host)
+	if err != nil {
+		result.status = "Unable to check tag: " + err.Error()
+		return result
+	}
+	state, err := client.ValidateTag(ctx, release.Tag{Name: result.tag, Hash: p.hash, Notes: latest.Notes})
+	switch {
+	case err != nil:
+		result.status = "Unable to check tag: " + err.Error()
+	case state == release.TagMissing:
+		result.status, result.valid = "Tag `"+result.tag+"` will be created", true
+	case state == release.TagAtHash:
+		result.status, result.valid = "Tag `"+result.tag+"` already exists at this commit", true
+	default:
+		result.status = "Tag `" + result.tag + "` already exists at another commit, add a new version to " + current.changelog
+	}
+	return result
+}
+
+// previewComment describes the results as markdown for a pull request comment, starting with the marker
+func previewComment(hash string, results []previewResult) string {
+	lines := []string{previewMarker, "### Release preview", ""}
+	if len(hash) > 0 {
+		lines = append(lines, "For commit "+hash, "")
+	}
+	for _, result := range results {
+		heading := "#### "
+		if result.component.name != "" || result.tag == "" {
+			heading += result.component.label() + " "
+		}
+		if result.tag != "" {
+			heading += "`" + result.tag + "`"
+		}
+		status := "Passed"
+		if !result.valid {
+			status = "Failed"
+		}
+		lines = append(lines, strings.TrimSpace(heading), "", "**"+status+":** "+result.status, "")
+		if result.notes != "" {
+			lines = append(lines, "**Release notes**", "", result.notes, "")
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func checkPreviewFlags(p *Preview) []string {
+	var errors []string
+	if len(p.provider) == 0 {
+		errors = append(errors, "-provider required, pull requests are commented on with the provider API")
+	} else if ValidProvider(p.provider) {
+		// for valid providers check for the credentials of the authentication type and repo
+		errors = append(errors, p.checkAuthFlags(p.provider, p.username, p.password)...)
+		if len(p.repo) == 0 {
+			errors = append(errors, "-repo required")
+		}
+		errors = append(errors, checkRepoFlag(p.provider, p.repo, p.host)...)
+	} else {
+		// valid provider values
+		errors = append(errors, "-provider valid values are "+strings.Join(providers[:], ", "))
+	}
+	errors = append(errors, p.checkClientFlags()...)
+	// changelog, hash and pull request are mandatory
+	if len(p.changelog) == 0 && len(p.configFile) == 0 {
+		errors = append(errors, "-changelog required")
+	}
+	if len(p.hash) == 0 {
+		errors = append(errors, "-hash required")
+	}
+	if p.pullRequest <= 0 {
+		errors = append(errors, "-pr required")
+	}
+	return errors
+}
+
+// newPreviewProvider sets up the provider the pull request is commented on with
+func newPreviewProvider(p *Preview) (release.Commenter, error) {
+	client, err := p.releaseClient(&p.clientFlags, p.provider, p.username, p.password, p.repo, p.host)
+	if err != nil {
+		return nil, err
+	}
+	return client.(release.Commenter), nil
+}
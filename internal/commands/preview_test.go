@@ -0,0 +1,79 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/google/subcommands"
+	"github.com/sanjP10/release/internal/tag/providers/github"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func TestPreview_Name(t *testing.T) {
+	preview := &Preview{}
+	assert.Equal(t, "preview", preview.Name())
+}
+
+func Test_checkPreviewFlags(t *testing.T) {
+	assertTest := assert.New(t)
+	preview := &Preview{}
+	assertTest.Equal([]string{"-provider required, pull requests are commented on with the provider API", "-changelog required", "-hash required", "-pr required"}, checkPreviewFlags(preview))
+
+	preview.provider = "gitlab"
+	preview.password = "token"
+	preview.repo = "group/repo"
+	preview.changelog = "CHANGELOG.md"
+	preview.hash = "hash"
+	preview.pullRequest = 12
+	assertTest.Empty(checkPreviewFlags(preview))
+}
+
+func Test_previewComment(t *testing.T) {
+	results := []previewResult{
+		{component: component{changelog: "CHANGELOG.md"}, tag: "1.2.0", notes: "### Added\n* A feature", status: "Tag `1.2.0` will be created", valid: true},
+		{component: component{name: "auth", changelog: "auth/CHANGELOG.md"}, status: "Unable to read changelog auth/CHANGELOG.md"},
+	}
+	assert.Equal(t, previewMarker+"\n### Release preview\n\nFor commit hash\n\n"+
+		"#### `1.2.0`\n\n**Passed:** Tag `1.2.0` will be created\n\n**Release notes**\n\n### Added\n* A feature\n\n"+
+		"#### auth\n\n**Failed:** Unable to read changelog auth/CHANGELOG.md\n", previewComment("hash", results))
+}
+
+func Test_PreviewGithub(t *testing.T) {
+	changelogPath := filepath.Join(t.TempDir(), "CHANGELOG.md")
+	writeChangelog(t, changelogPath, "1.2.0", "1.1.0")
+	var updated github.IssueComment
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /api/v3/repos/owner/repo/git/refs/tags/1.2.0":
+			w.WriteHeader(http.StatusNotFound)
+		case "GET /api/v3/repos/owner/repo/issues/12/comments":
+			_ = json.NewEncoder(w).Encode([]github.IssueComment{{ID: 6, Body: "LGTM"}, {ID: 7, Body: previewMarker + "\nold preview"}})
+		case "PATCH /api/v3/repos/owner/repo/issues/comments/7":
+			_ = json.NewDecoder(r.Body).Decode(&updated)
+			_ = json.NewEncoder(w).Encode(github.IssueComment{ID: 7})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	preview := &Preview{}
+	preview.provider = "github"
+	// a trailing slash on the host is ignored as in the other commands
+	preview.host = server.URL + "/"
+	preview.authType = "bearer"
+	preview.password = "token"
+	preview.repo = "owner/repo"
+	preview.changelog = changelogPath
+	preview.hash = "hash"
+	preview.pullRequest = 12
+	assertTest := assert.New(t)
+	assertTest.Equal(subcommands.ExitSuccess, preview.Execute(context.Background(), nil))
+	assertTest.Contains(updated.Body, previewMarker)
+	assertTest.Contains(updated.Body, "**Passed:** Tag `1.2.0` will be created")
+	assertTest.Contains(updated.Body, "* Changes in 1.2.0")
+}
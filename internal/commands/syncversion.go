@@ -0,0 +1,201 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"github.com/google/subcommands"
+	"github.com/sanjP10/release/internal/versionfile"
+	"github.com/sanjP10/release/pkg/release"
+	"os"
+	"strings"
+)
+
+// SyncVersion for sync-version sub command
+type SyncVersion struct {
+	componentFlags
+	versionFileFlags
+	changelog string
+	check     bool
+}
+
+// versionFileFlags select project files holding the version, adding to the version files of the config file
+type versionFileFlags struct {
+	versionFiles stringList
+}
+
+// Name of sub command
+func (*SyncVersion) Name() string { return "sync-version" }
+
+// Synopsis of sub command
+func (*SyncVersion) Synopsis() string { return "Writes the changelog version to project files." }
+
+// Usage of sub command
+func (*SyncVersion) Usage() string {
+	return "Writes the top version of the changelog to the version of project files such as package.json, pyproject.toml or Chart.yaml.\n"
+}
+
+// SetFlags required for sync-version sub command
+func (s *SyncVersion) SetFlags(f *flag.FlagSet) {
+	f.StringVar(&s.changelog, "changelog", "", "Location of changelog markdown file")
+	f.BoolVar(&s.check, "check", false, "Only check the project files hold the changelog version, failing when any disagree")
+	s.setVersionFileFlags(f)
+	s.setComponentFlags(f)
+}
+
+func (vf *versionFileFlags) setVersionFileFlags(f *flag.FlagSet) {
+	f.Var(&vf.versionFiles, "version-file", "Project file holding the version as path or path=key, the key being the dotted path of the version in JSON, YAML and TOML files. The path can be a glob. Can be given several times")
+}
+
+// flagVersionFiles parses the -version-file flags
+func (vf *versionFileFlags) flagVersionFiles() []versionfile.File {
+	var files []versionfile.File
+	for _, flagValue := range vf.versionFiles {
+		path, key, _ := strings.Cut(flagValue, "=")
+		files = append(files, versionfile.File{Path: path, Key: key})
+	}
+	return files
+}
+
+func (vf *versionFileFlags) checkVersionFileFlags() []string {
+	var errors []string
+	for _, file := range vf.flagVersionFiles() {
+		if err := file.Validate(); err != nil {
+			errors = append(errors, "-version-file "+err.Error())
+		}
+	}
+	return errors
+}
+
+// componentVersionFiles are the version files of the component with their globs expanded, the flags only apply to a
+// single changelog
+func (vf *versionFileFlags) componentVersionFiles(current component, components []component) ([]versionfile.File, error) {
+	var files []versionfile.File
+	if len(vf.versionFiles) > 0 {
+		if len(components) > 1 {
+			return nil, errors.New("-version-file is for a single changelog, set versionFiles on each component in -config instead")
+		}
+		files = vf.flagVersionFiles()
+	}
+	for _, configured := range current.versionFiles {
+		files = append(files, versionfile.File{Path: configured.Path, Format: configured.Format, Key: configured.Key, Pattern: configured.Pattern})
+	}
+	return versionfile.Expand(files)
+}
+
+// versionFileProblems describes the version files that do not hold the version
+func versionFileProblems(files []versionfile.File, version string) []string {
+	var problems []string
+	for _, file := range files {
+		current, err := file.Read()
+		if err != nil {
+			problems = append(problems, "Unable to read version file "+err.Error())
+		} else if current != version {
+			problems = append(problems, file.Path+" has version "+current+", the changelog is at "+version+". Run release sync-version to update it")
+		}
+	}
+	return problems
+}
+
+// Execute flow for sync-version sub command
+func (s *SyncVersion) Execute(_ context.Context, _ *flag.FlagSet, _ ...interface{}) subcommands.ExitStatus {
+	exit := subcommands.ExitSuccess
+	errors := checkSyncVersionFlags(s)
+	if len(errors) > 0 {
+		errors = append(errors, "\n")
+		_, err := os.Stderr.WriteString("missing flags for sync-version:\n" + strings.Join(errors, "\n"))
+		if err != nil {
+			panic("Cannot write to stderr")
+		}
+		return subcommands.ExitUsageError
+	}
+	components, err := s.components(s.changelog)
+	if err != nil {
+		_, err := os.Stderr.WriteString(err.Error() + "\n")
+		if err != nil {
+			panic("Cannot write to stderr")
+		}
+		return subcommands.ExitUsageError
+	}
+	synced := 0
+	for _, current := range components {
+		count, status := s.syncComponent(current, components)
+		synced += count
+		if status != subcommands.ExitSuccess {
+			exit = status
+		}
+	}
+	if synced == 0 && exit == subcommands.ExitSuccess {
+		exit = subcommands.ExitUsageError
+		_, err := os.Stderr.WriteString("No version files, set -version-file or versionFiles in -config\n")
+		if err != nil {
+			panic("Cannot write to stderr")
+		}
+	}
+	return exit
+}
+
+// syncComponent writes or checks the version of the component changelog in its version files, returning how many
+// files were handled
+func (s *SyncVersion) syncComponent(current component, components []component) (int, subcommands.ExitStatus) {
+	changelogObj, err := release.ReadChangelog(current.changelog)
+	if err != nil {
+		_, err := os.Stderr.WriteString("Unable to read changelog " + current.changelog + "\n")
+		if err != nil {
+			panic("Cannot write to stderr")
+		}
+		return 0, subcommands.ExitUsageError
+	}
+	latest, ok := changelogObj.Latest()
+	version := latest.Version
+	files, err := s.componentVersionFiles(current, components)
+	if err == nil && !ok && len(files) > 0 {
+		err = errors.New("no version found in changelog " + current.changelog)
+	}
+	if err != nil {
+		_, err := os.Stderr.WriteString(err.Error() + "\n")
+		if err != nil {
+			panic("Cannot write to stderr")
+		}
+		return 0, subcommands.ExitFailure
+	}
+	exit := subcommands.ExitSuccess
+	if s.check {
+		for _, problem := range versionFileProblems(files, version) {
+			exit = subcommands.ExitFailure
+			_, err := os.Stderr.WriteString(problem + "\n")
+			if err != nil {
+				panic("Cannot write to stderr")
+			}
+		}
+		return len(files), exit
+	}
+	for _, file := range files {
+		changed, err := file.Write(version)
+		message := file.Path + " is at " + version + "\n"
+		if err != nil {
+			exit = subcommands.ExitFailure
+			_, err := os.Stderr.WriteString("Unable to update version file " + err.Error() + "\n")
+			if err != nil {
+				panic("Cannot write to stderr")
+			}
+			continue
+		}
+		if changed {
+			message = "Updated " + file.Path + " to " + version + "\n"
+		}
+		_, err = os.Stdout.WriteString(message)
+		if err != nil {
+			panic("Cannot write to stdout")
+		}
+	}
+	return len(files), exit
+}
+
+func checkSyncVersionFlags(s *SyncVersion) []string {
+	var errors []string
+	if len(s.changelog) == 0 && len(s.configFile) == 0 {
+		errors = append(errors, "-changelog required")
+	}
+	return append(errors, s.checkVersionFileFlags()...)
+}
@@ -0,0 +1,84 @@
+package commands
+
+import (
+	"context"
+	"github.com/google/subcommands"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSyncVersion_Name(t *testing.T) {
+	assert.Equal(t, "sync-version", (&SyncVersion{}).Name())
+}
+
+func Test_SyncVersion(t *testing.T) {
+	assertTest := assert.New(t)
+	t.Chdir(t.TempDir())
+	writeChangelog(t, "CHANGELOG.md", "1.2.0", "1.1.0")
+	assertTest.NoError(os.WriteFile("package.json", []byte("{\n  \"version\": \"1.1.0\"\n}\n"), 0600))
+	assertTest.NoError(os.WriteFile("version.go", []byte("const version = \"1.1.0\"\n"), 0600))
+	assertTest.NoError(os.WriteFile("release.yaml", []byte(`
+versionFiles:
+  - path: version.go
+    pattern: 'version = "(.*)"'
+`), 0600))
+
+	sync := &SyncVersion{}
+	sync.changelog = "CHANGELOG.md"
+	sync.configFile = "release.yaml"
+	sync.versionFiles = stringList{"package.json"}
+	sync.check = true
+	assertTest.Equal(subcommands.ExitFailure, sync.Execute(context.Background(), nil))
+
+	sync.check = false
+	assertTest.Equal(subcommands.ExitSuccess, sync.Execute(context.Background(), nil))
+	content, _ := os.ReadFile("package.json")
+	assertTest.Equal("{\n  \"version\": \"1.2.0\"\n}\n", string(content))
+	content, _ = os.ReadFile("version.go")
+	assertTest.Equal("const version = \"1.2.0\"\n", string(content))
+
+	sync.check = true
+	assertTest.Equal(subcommands.ExitSuccess, sync.Execute(context.Background(), nil))
+}
+
+func Test_SyncVersionFlags(t *testing.T) {
+	assertTest := assert.New(t)
+	sync := &SyncVersion{}
+	sync.versionFiles = stringList{"VERSION"}
+	assertTest.Equal([]string{"-changelog required", "-version-file VERSION: unknown format, set a format of json, yaml, toml or regex"}, checkSyncVersionFlags(sync))
+
+	// without version files there is nothing to sync
+	t.Chdir(t.TempDir())
+	writeChangelog(t, "CHANGELOG.md", "1.0.0")
+	sync = &SyncVersion{}
+	sync.changelog = "CHANGELOG.md"
+	assertTest.Equal(subcommands.ExitUsageError, sync.Execute(context.Background(), nil))
+
+	// flags cannot tell the components of a glob apart
+	writeChangelog(t, filepath.Join("api", "CHANGELOG.md"), "1.0.0")
+	writeChangelog(t, filepath.Join("web", "CHANGELOG.md"), "1.0.0")
+	sync.changelog = filepath.Join("*", "CHANGELOG.md")
+	sync.versionFiles = stringList{"package.json"}
+	assertTest.Equal(subcommands.ExitFailure, sync.Execute(context.Background(), nil))
+}
+
+func Test_ValidateVersionFiles(t *testing.T) {
+	assertTest := assert.New(t)
+	originPath, _, hash := initOrigin(t)
+	t.Chdir(t.TempDir())
+	writeChangelog(t, "CHANGELOG.md", "1.0.0")
+	assertTest.NoError(os.WriteFile("Chart.yaml", []byte("name: app\nversion: 0.9.0\n"), 0600))
+
+	validate := &Validate{}
+	validate.origin = originPath
+	validate.email = "tester@example.com"
+	validate.hash = hash.String()
+	validate.changelog = "CHANGELOG.md"
+	validate.versionFiles = stringList{"Chart.yaml"}
+	assertTest.Equal(subcommands.ExitFailure, validate.Execute(context.Background(), nil))
+
+	assertTest.NoError(os.WriteFile("Chart.yaml", []byte("name: app\nversion: 1.0.0\n"), 0600))
+	assertTest.Equal(subcommands.ExitSuccess, validate.Execute(context.Background(), nil))
+}
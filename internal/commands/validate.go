@@ -4,28 +4,37 @@ import (
 	"context"
 	"flag"
 	"github.com/google/subcommands"
-	"github.com/sanjP10/release/internal/changelog"
-	"github.com/sanjP10/release/internal/tag"
-	"github.com/sanjP10/release/internal/tag/providers/bitbucket"
 	"github.com/sanjP10/release/internal/tag/providers/git"
-	"github.com/sanjP10/release/internal/tag/providers/github"
-	"github.com/sanjP10/release/internal/tag/providers/gitlab"
+	"github.com/sanjP10/release/pkg/release"
 	"os"
+	"path"
 	"strings"
 )
 
 // Validate for validate sub command
 type Validate struct {
-	username  string
-	password  string
-	email     string
-	changelog string
-	repo      string
-	hash      string
-	host      string
-	origin    string
-	provider  string
-	ssh       string
+	clientFlags
+	authFlags
+	componentFlags
+	versionFileFlags
+	username              string
+	password              string
+	email                 string
+	changelog             string
+	repo                  string
+	hash                  string
+	host                  string
+	origin                string
+	provider              string
+	ssh                   string
+	repoPath              string
+	httpAuth              string
+	knownHosts            string
+	insecureIgnoreHostKey bool
+	requireSigned         bool
+	keyring               string
+	requireBump           bool
+	bumpPaths             string
 }
 
 // Name of subcommand
@@ -51,10 +60,22 @@ func (v *Validate) SetFlags(f *flag.FlagSet) {
 	f.StringVar(&v.origin, "origin", "", "HTTPS or SSH origin of git repository, to be provided when the provider flag is not provided")
 	f.StringVar(&v.provider, "provider", "", "The Git provider, options are github, gitlab or bitbucket, when providing this flag you will be using their APIs")
 	f.StringVar(&v.ssh, "ssh", "", "SSH private key file location, please provide Username and password of the SSH file if required. Username defaults to git. This is to be used when the provider flag is not provided")
+	f.StringVar(&v.httpAuth, "http-auth", "basic", "Authentication scheme for HTTPS origins, options are basic or bearer (the password is sent as the token). This is to be used when the provider flag is not provided")
+	f.StringVar(&v.knownHosts, "known-hosts", "", "known_hosts file used to verify the SSH host key, defaults to SSH_KNOWN_HOSTS or ~/.ssh/known_hosts. This is to be used when the provider flag is not provided")
+	f.BoolVar(&v.insecureIgnoreHostKey, "insecure-ignore-host-key", false, "Skip SSH host key verification, only use this in throwaway environments")
+	f.StringVar(&v.repoPath, "repo-path", "", "Path to an existing local clone, its origin and configured credentials are used instead of fetching the repository into memory. This is to be used when the provider flag is not provided")
+	f.BoolVar(&v.requireSigned, "require-signed", false, "Require an existing tag to carry a valid signature from a key in -keyring. This is to be used when the provider flag is not provided")
+	f.BoolVar(&v.requireBump, "require-bump", false, "When the tag of the top changelog version exists at another commit, compare it with -hash and fail if files changed, asking for a new changelog version. Validation passes when nothing changed")
+	f.StringVar(&v.bumpPaths, "bump-paths", "", "Comma separated globs, relative to the repository root for a single changelog or to the component path, of the files that require a version bump with -require-bump, e.g. src/*,go.mod. Defaults to every file")
+	f.StringVar(&v.keyring, "keyring", "", "Armored OpenPGP public keyring or SSH public keys file (authorized_keys or allowed_signers format) used to verify signed tags")
+	v.setVersionFileFlags(f)
+	v.setComponentFlags(f)
+	v.setAuthFlags(f)
+	v.setClientFlags(f)
 }
 
 // Execute flow of subcommand
-func (v *Validate) Execute(_ context.Context, _ *flag.FlagSet, _ ...interface{}) subcommands.ExitStatus {
+func (v *Validate) Execute(ctx context.Context, _ *flag.FlagSet, _ ...interface{}) subcommands.ExitStatus {
 	exit := subcommands.ExitSuccess
 	errors := checkValidateFlags(v)
 	if len(errors) > 0 {
@@ -65,83 +86,185 @@ func (v *Validate) Execute(_ context.Context, _ *flag.FlagSet, _ ...interface{})
 			panic("Cannot write to stderr")
 		}
 	} else {
-		changelogFile, err := changelog.ReadChangelogAsString(v.changelog)
+		ctx, cancel := v.withDeadline(ctx)
+		defer cancel()
+		components, err := v.components(v.changelog)
 		if err != nil {
 			exit = subcommands.ExitUsageError
-			_, err := os.Stderr.WriteString("Unable to read changelog\n")
+			_, err := os.Stderr.WriteString(err.Error() + "\n")
 			if err != nil {
 				panic("Cannot write to stderr")
 			}
+		} else if !monorepo(components) {
+			exit = v.validateComponent(ctx, components[0], components).exit
 		} else {
-			changelogObj := changelog.Properties{}
-			changelogObj.GetVersions(changelogFile)
-			validSemantics := changelogObj.ValidateVersionSemantics()
-			if !validSemantics {
-				exit = subcommands.ExitFailure
-				_, err := os.Stderr.WriteString("Invalid version semantics\n")
-				if err != nil {
-					panic("Cannot write to stderr")
-				}
-			} else {
-				desiredTag := changelogObj.ConvertToDesiredTag()
-				success, err := validateProviderTag(v, desiredTag, changelogObj)
-				if err != nil {
-					_, err := os.Stderr.WriteString("Error validating tag with repo " + v.origin + " " + err.Error() + "\n")
-					if err != nil {
-						panic("Cannot write to stderr")
-					}
-					exit = subcommands.ExitFailure
-				} else if !success {
-					_, err := os.Stderr.WriteString("Tag " + strings.TrimSpace(desiredTag) + " already exists\n")
-					if err != nil {
-						panic("Cannot write to stderr")
-					}
-					exit = subcommands.ExitFailure
-				} else {
-					_, err := os.Stdout.WriteString(strings.TrimSpace(desiredTag) + "\n")
-					if err != nil {
-						panic("Cannot write to stderr")
-					}
+			summary := releaseSummary{}
+			for _, current := range components {
+				summary.add(v.validateComponent(ctx, current, components))
+				if ctx.Err() != nil {
+					break
 				}
 			}
+			summary.write()
+			if summary.failed() {
+				exit = subcommands.ExitFailure
+			}
 		}
 	}
 	return exit
 }
 
+// validateComponent checks the tag of the top version of the component changelog can be created and its version files
+// hold the version, in a monorepo an existing tag at the hash is skipped
+func (v *Validate) validateComponent(ctx context.Context, current component, components []component) componentResult {
+	result := componentResult{component: current, outcome: outcomeFailed, exit: subcommands.ExitFailure}
+	changelogObj, err := release.ReadChangelog(current.changelog)
+	if err != nil {
+		result.exit = subcommands.ExitUsageError
+		result.reason = "unable to read changelog"
+		_, err := os.Stderr.WriteString("Unable to read changelog " + current.changelog + "\n")
+		if err != nil {
+			panic("Cannot write to stderr")
+		}
+		return result
+	}
+	err = changelogObj.Validate()
+	if err != nil {
+		result.reason = "invalid version semantics"
+		_, err := os.Stderr.WriteString("Invalid version semantics, " + err.Error() + "\n")
+		if err != nil {
+			panic("Cannot write to stderr")
+		}
+		return result
+	}
+	latest, _ := changelogObj.Latest()
+	desiredTag := current.tag(latest.Version)
+	result.tag = desiredTag
+	if !v.checkVersionFiles(current, components, latest.Version) {
+		result.reason = "version files disagree with the changelog"
+		return result
+	}
+	releaseTag := release.Tag{Name: desiredTag, Hash: v.hash, Notes: latest.Notes}
+	client, state, err := validateProviderTag(ctx, v, releaseTag)
+	if reportInterrupted(ctx, "validating tag "+desiredTag) {
+		result.reason = "interrupted"
+	} else if err != nil {
+		result.reason = err.Error()
+		_, err := os.Stderr.WriteString("Error validating tag with repo " + v.repoName() + " " + err.Error() + "\n")
+		if err != nil {
+			panic("Cannot write to stderr")
+		}
+	} else if state == release.TagAtOtherCommit && v.requireBump {
+		return unchangedSinceTag(ctx, client, releaseTag, result, v.bumpGlobs()...)
+	} else if state == release.TagAtOtherCommit {
+		result.reason = "tag already exists"
+		_, err := os.Stderr.WriteString("Tag " + desiredTag + " already exists\n")
+		if err != nil {
+			panic("Cannot write to stderr")
+		}
+	} else {
+		result.outcome, result.exit = outcomeReady, subcommands.ExitSuccess
+		if monorepo(components) && state == release.TagAtHash {
+			result.outcome, result.reason = outcomeSkipped, "already released"
+		}
+		_, err := os.Stdout.WriteString(desiredTag + "\n")
+		if err != nil {
+			panic("Cannot write to stderr")
+		}
+	}
+	return result
+}
+
+// checkVersionFiles reports the version files of the component that do not hold the version
+func (v *Validate) checkVersionFiles(current component, components []component, version string) bool {
+	files, err := v.componentVersionFiles(current, components)
+	problems := versionFileProblems(files, version)
+	if err != nil {
+		problems = []string{err.Error()}
+	}
+	for _, problem := range problems {
+		_, err := os.Stderr.WriteString(problem + "\n")
+		if err != nil {
+			panic("Cannot write to stderr")
+		}
+	}
+	return len(problems) == 0
+}
+
+// repoName describes the repository being validated for error messages
+func (v *Validate) repoName() string {
+	switch {
+	case len(v.provider) > 0:
+		return v.repo
+	case len(v.origin) > 0:
+		return v.origin
+	}
+	return v.repoPath
+}
+
+// bumpGlobs splits the -bump-paths globs
+func (v *Validate) bumpGlobs() []string {
+	var globs []string
+	for _, glob := range strings.Split(v.bumpPaths, ",") {
+		if glob = strings.TrimSpace(glob); glob != "" {
+			globs = append(globs, glob)
+		}
+	}
+	return globs
+}
+
 func checkValidateFlags(v *Validate) []string {
 	var errors []string
 	if len(v.provider) == 0 {
-		// Use regular git. Check for origin, username/ssh and email
-		if len(v.origin) == 0 {
+		// Use regular git. Check for origin, username/ssh and email, a local clone provides its own origin and credentials
+		if len(v.origin) == 0 && len(v.repoPath) == 0 {
 			errors = append(errors, "-origin required")
 		}
-		if len(v.username) == 0 && len(v.ssh) == 0 {
+		// without explicit credentials ssh origins use ssh-agent and https origins use git credential helpers or netrc
+		implicitAuth := len(v.origin) > 0 || len(v.repoPath) > 0
+		if len(v.username) == 0 && len(v.ssh) == 0 && !implicitAuth {
 			errors = append(errors, "-username or -ssh required, for CodeCommit or GCP Source repositories both are required")
 		}
-		if len(v.password) == 0 && len(v.ssh) == 0 {
+		if len(v.password) == 0 && len(v.ssh) == 0 && !implicitAuth {
 			errors = append(errors, "-password required")
 		}
-		if len(v.email) == 0 {
+		if len(v.email) == 0 && len(v.repoPath) == 0 {
 			errors = append(errors, "-email required")
 		}
 	} else if ValidProvider(v.provider) {
-		// for valid providers check for username and repo
-		if len(v.username) == 0 && strings.ToLower(v.provider) != "gitlab" {
-			errors = append(errors, "-username required")
-		}
-		if len(v.password) == 0 {
-			errors = append(errors, "-password required")
-		}
+		// for valid providers check for the credentials of the authentication type and repo
+		errors = append(errors, v.checkAuthFlags(v.provider, v.username, v.password)...)
 		if len(v.repo) == 0 {
 			errors = append(errors, "-repo required")
 		}
+		errors = append(errors, checkRepoFlag(v.provider, v.repo, v.host)...)
 	} else {
 		// valid provider values
 		errors = append(errors, "-provider valid values are "+strings.Join(providers[:], ", "))
 	}
+	if v.requireSigned {
+		if len(v.provider) > 0 {
+			errors = append(errors, "-require-signed is only supported when the provider flag is not supplied")
+		}
+		if len(v.keyring) == 0 {
+			errors = append(errors, "-keyring required when -require-signed is set")
+		}
+	}
+	if len(v.bumpPaths) > 0 && !v.requireBump {
+		errors = append(errors, "-bump-paths is only used with -require-bump")
+	}
+	for _, glob := range v.bumpGlobs() {
+		if _, err := path.Match(glob, ""); err != nil {
+			errors = append(errors, "-bump-paths glob "+glob+" is invalid")
+		}
+	}
+	if !git.ValidHTTPAuth(v.httpAuth) {
+		errors = append(errors, "-http-auth valid values are "+git.HTTPAuthBasic+", "+git.HTTPAuthBearer)
+	}
+	errors = append(errors, v.checkClientFlags()...)
+	errors = append(errors, v.checkVersionFileFlags()...)
 	// changelog and hash are mandatory
-	if len(v.changelog) == 0 {
+	if len(v.changelog) == 0 && len(v.configFile) == 0 {
 		errors = append(errors, "-changelog required")
 	}
 	if len(v.hash) == 0 {
@@ -150,33 +273,36 @@ func checkValidateFlags(v *Validate) []string {
 	return errors
 }
 
-func validateProviderTag(v *Validate, desiredTag string, changelogObj changelog.Properties) (bool, error) {
-	success := false
-	validTagState := tag.ValidTagState{}
-	properties := tag.RepoProperties{
-		Password: v.password,
-		Tag:      strings.TrimSpace(desiredTag),
-		Hash:     v.hash,
-		Body:     changelogObj.Changes,
-	}
-	switch strings.ToLower(v.provider) {
-	case "github":
-		provider := github.Properties{Username: v.username, Repo: v.repo, Host: v.host, RepoProperties: properties}
-		validTagState = provider.ValidateTag()
-	case "gitlab":
-		provider := gitlab.Properties{Repo: v.repo, Host: v.host, RepoProperties: properties}
-		validTagState = provider.ValidateTag()
-	case "bitbucket":
-		provider := bitbucket.Properties{Username: v.username, Repo: v.repo, Host: v.host, RepoProperties: properties}
-		validTagState = provider.ValidateTag()
-	default:
-		provider := git.Properties{Username: v.username, Email: v.email, Origin: v.origin, SSH: v.ssh, RepoProperties: properties}
-		err := provider.InitializeRepository()
-		if err != nil {
-			return false, err
-		}
-		validTagState = provider.ValidateTag()
+// validateProviderTag reports whether the tag exists with the provider it was checked with, the error explains why it
+// could not be checked or why its signature was rejected
+func validateProviderTag(ctx context.Context, v *Validate, releaseTag release.Tag) (release.Client, release.TagState, error) {
+	var client release.Client
+	var err error
+	if len(v.provider) > 0 {
+		client, err = v.releaseClient(&v.clientFlags, v.provider, v.username, v.password, v.repo, v.host)
+	} else {
+		client, err = release.NewGit(release.GitOptions{
+			Origin:                v.origin,
+			RepoPath:              v.repoPath,
+			Username:              v.username,
+			Password:              v.password,
+			BearerToken:           strings.ToLower(v.httpAuth) == git.HTTPAuthBearer,
+			SSHKey:                v.ssh,
+			KnownHosts:            v.knownHosts,
+			InsecureIgnoreHostKey: v.insecureIgnoreHostKey,
+			Email:                 v.email,
+		})
+	}
+	if err != nil {
+		return nil, release.TagMissing, err
+	}
+	// a permissions problem or outage is returned as an error rather than reported as an existing tag
+	state, err := client.ValidateTag(ctx, releaseTag)
+	if err != nil {
+		return client, state, err
+	}
+	if verifier, ok := client.(release.SignatureVerifier); ok && v.requireSigned && state == release.TagAtHash {
+		err = verifier.VerifyTag(ctx, releaseTag, v.keyring)
 	}
-	success = validTagState.TagDoesntExist || validTagState.TagExistsWithProvidedHash
-	return success, nil
+	return client, state, err
 }
@@ -203,3 +203,96 @@ func Test_ValidateCheckFlag_Gitlab(t *testing.T) {
 	errors := checkValidateFlags(validate)
 	assertTest.Empty(errors)
 }
+
+func Test_ValidateCheckFlag_RequireSigned(t *testing.T) {
+	validate := &Validate{}
+	validate.password = "token"
+	validate.provider = "gitlab"
+	validate.repo = "repo"
+	validate.hash = "hash"
+	validate.changelog = "file"
+	validate.requireSigned = true
+	assertTest := assert.New(t)
+	expected := []string{
+		"-require-signed is only supported when the provider flag is not supplied",
+		"-keyring required when -require-signed is set"}
+	assertTest.Equal(expected, checkValidateFlags(validate))
+}
+
+func Test_ValidateCheckFlag_RepoPath(t *testing.T) {
+	validate := &Validate{}
+	validate.repoPath = "."
+	validate.hash = "hash"
+	validate.changelog = "file"
+	assertTest := assert.New(t)
+	assertTest.Empty(checkValidateFlags(validate))
+}
+
+func Test_ValidateCheckFlag_SSHAgent(t *testing.T) {
+	validate := &Validate{}
+	validate.origin = "git@github.com:owner/repo.git"
+	validate.email = "user@example.com"
+	validate.hash = "hash"
+	validate.changelog = "file"
+	assertTest := assert.New(t)
+	assertTest.Empty(checkValidateFlags(validate))
+}
+
+func Test_ValidateCheckFlag_ClientCert(t *testing.T) {
+	validate := &Validate{}
+	validate.password = "token"
+	validate.provider = "gitlab"
+	validate.repo = "repo"
+	validate.hash = "hash"
+	validate.changelog = "file"
+	validate.clientCert = "cert.pem"
+	assertTest := assert.New(t)
+	assertTest.Equal([]string{"-client-cert and -client-key must be provided together"}, checkValidateFlags(validate))
+}
+
+func Test_ValidateCheckFlag_AuthTypeInvalid(t *testing.T) {
+	validate := &Validate{}
+	validate.provider = "bitbucket"
+	validate.username = "username"
+	validate.password = "password"
+	validate.repo = "owner/repo"
+	validate.hash = "hash"
+	validate.changelog = "file"
+	validate.authType = "app"
+	assert.New(t).Equal([]string{"-auth-type valid values for bitbucket are basic, bearer"}, checkValidateFlags(validate))
+}
+
+func Test_ValidateCheckFlag_GitlabJobToken(t *testing.T) {
+	validate := &Validate{}
+	validate.provider = "gitlab"
+	validate.repo = "group/repo"
+	validate.hash = "hash"
+	validate.changelog = "file"
+	assertTest := assert.New(t)
+
+	t.Setenv("CI_JOB_TOKEN", "")
+	assertTest.Equal([]string{"-password required"}, checkValidateFlags(validate))
+	t.Setenv("CI_JOB_TOKEN", "job-token")
+	assertTest.Empty(checkValidateFlags(validate))
+
+	validate.authType = "bearer"
+	assertTest.Equal([]string{"-password required"}, checkValidateFlags(validate))
+
+	validate.authType = "basic"
+	assertTest.Equal([]string{"-auth-type valid values for gitlab are private-token, job-token, bearer"}, checkValidateFlags(validate))
+}
+
+func Test_ValidateCheckFlag_BumpPaths(t *testing.T) {
+	validate := &Validate{}
+	validate.repoPath = "."
+	validate.hash = "hash"
+	validate.changelog = "file"
+	validate.bumpPaths = "src/*, ["
+	assertTest := assert.New(t)
+	assertTest.Equal([]string{"-bump-paths is only used with -require-bump", "-bump-paths glob [ is invalid"}, checkValidateFlags(validate))
+
+	validate.requireBump = true
+	validate.bumpPaths = "src/*, go.mod"
+	assertTest.Empty(checkValidateFlags(validate))
+	assertTest.Equal([]string{"src/*", "go.mod"}, validate.bumpGlobs())
+}
@@ -2,18 +2,21 @@ package commands
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
 	"github.com/google/subcommands"
+	"github.com/sanjP10/release/internal/buildinfo"
 	"os"
-	"strings"
 )
 
-// Version for validate sub command
+// Version for version sub command
 type Version struct {
+	json bool
 }
 
 // SetFlags of subcommand
-func (v *Version) SetFlags(_ *flag.FlagSet) {
+func (v *Version) SetFlags(f *flag.FlagSet) {
+	f.BoolVar(&v.json, "json", false, "Write the version, commit, build date and Go version as JSON")
 }
 
 // Name of subcommand
@@ -28,9 +31,18 @@ func (*Version) Usage() string {
 }
 
 // Execute flow of subcommand
-func (*Version) Execute(_ context.Context, _ *flag.FlagSet, _ ...interface{}) subcommands.ExitStatus {
+func (v *Version) Execute(_ context.Context, _ *flag.FlagSet, _ ...interface{}) subcommands.ExitStatus {
 	exit := subcommands.ExitSuccess
-	_, err := os.Stdout.WriteString(strings.TrimSpace("3.2.2") + "\n")
+	info := buildinfo.Get()
+	output := info.Version + "\n"
+	if v.json {
+		encoded, err := json.Marshal(info)
+		if err != nil {
+			panic("Cannot encode version")
+		}
+		output = string(encoded) + "\n"
+	}
+	_, err := os.Stdout.WriteString(output)
 	if err != nil {
 		panic("Cannot write to stderr")
 	}
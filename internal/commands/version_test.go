@@ -0,0 +1,61 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"github.com/google/subcommands"
+	"github.com/sanjP10/release/internal/buildinfo"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+// captureStdout returns what the function writes to stdout
+func captureStdout(t *testing.T, run func()) string {
+	return capture(t, &os.Stdout, run)
+}
+
+func captureStderr(t *testing.T, run func()) string {
+	return capture(t, &os.Stderr, run)
+}
+
+// capture collects what run writes to the file, such as os.Stdout
+func capture(t *testing.T, file **os.File, run func()) string {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	original := *file
+	*file = writer
+	defer func() { *file = original }()
+	run()
+	_ = writer.Close()
+	output, _ := io.ReadAll(reader)
+	return string(output)
+}
+
+func TestVersion_Execute(t *testing.T) {
+	assertTest := assert.New(t)
+	version := &Version{}
+	output := captureStdout(t, func() {
+		assertTest.Equal(subcommands.ExitSuccess, version.Execute(context.Background(), nil))
+	})
+	assertTest.Equal(buildinfo.Get().Version+"\n", output)
+}
+
+func TestVersion_JSON(t *testing.T) {
+	assertTest := assert.New(t)
+	version := &Version{}
+	flags := flag.NewFlagSet("version", flag.ContinueOnError)
+	version.SetFlags(flags)
+	assertTest.NoError(flags.Parse([]string{"-json"}))
+	output := captureStdout(t, func() {
+		assertTest.Equal(subcommands.ExitSuccess, version.Execute(context.Background(), flags))
+	})
+	info := buildinfo.Info{}
+	assertTest.NoError(json.Unmarshal([]byte(strings.TrimSpace(output)), &info))
+	assertTest.Equal(buildinfo.Get(), info)
+}
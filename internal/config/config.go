@@ -0,0 +1,112 @@
+// Package config reads the release configuration file
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io"
+	"net/url"
+	"os"
+)
+
+// VersionFile is a project file holding the version, written by sync-version and checked by validate
+type VersionFile struct {
+	// Path of the file, a glob matches several files
+	Path string `yaml:"path"`
+	// Format is json, yaml, toml or regex, detected from the extension when empty
+	Format string `yaml:"format"`
+	// Key is the dotted path of the version in JSON, YAML and TOML files
+	Key string `yaml:"key"`
+	// Pattern is a regular expression whose first group holds the version
+	Pattern string `yaml:"pattern"`
+}
+
+// Component is a part of a monorepo released from its own changelog with its own tags
+type Component struct {
+	Name      string `yaml:"name"`
+	Changelog string `yaml:"changelog"`
+	// Path is the directory of the component, defaults to the directory of the changelog
+	Path string `yaml:"path"`
+	// TagTemplate overrides the tag template of the configuration for this component
+	TagTemplate  string        `yaml:"tagTemplate"`
+	VersionFiles []VersionFile `yaml:"versionFiles"`
+}
+
+// Notifications announce created releases, each list holds the URLs of incoming webhooks
+type Notifications struct {
+	Webhooks []string `yaml:"webhooks"`
+	Slack    []string `yaml:"slack"`
+	Teams    []string `yaml:"teams"`
+	// Strict fails the release when a notification fails
+	Strict bool `yaml:"strict"`
+}
+
+// Hooks are shell commands run by create around validating and creating each tag
+type Hooks struct {
+	PreValidate []string `yaml:"preValidate"`
+	PreCreate   []string `yaml:"preCreate"`
+	PostCreate  []string `yaml:"postCreate"`
+}
+
+// Config of release, paths are relative to the working directory
+type Config struct {
+	// TagTemplate builds tags from {component} and {version}
+	TagTemplate   string        `yaml:"tagTemplate"`
+	Components    []Component   `yaml:"components"`
+	Notifications Notifications `yaml:"notifications"`
+	Hooks         Hooks         `yaml:"hooks"`
+	// VersionFiles hold the version of the changelog when it is not split into components
+	VersionFiles []VersionFile `yaml:"versionFiles"`
+}
+
+// Load reads the configuration file, unknown fields are rejected so typos are not silently ignored
+func Load(path string) (Config, error) {
+	config := Config{}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return config, err
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	err = decoder.Decode(&config)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return config, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	for i, component := range config.Components {
+		if component.Name == "" {
+			return config, fmt.Errorf("invalid config %s: component %d has no name", path, i+1)
+		}
+		if component.Changelog == "" {
+			return config, fmt.Errorf("invalid config %s: component %s has no changelog", path, component.Name)
+		}
+		for _, versionFile := range component.VersionFiles {
+			if versionFile.Path == "" {
+				return config, fmt.Errorf("invalid config %s: a version file of component %s has no path", path, component.Name)
+			}
+		}
+	}
+	if len(config.VersionFiles) > 0 && len(config.Components) > 0 {
+		return config, fmt.Errorf("invalid config %s: versionFiles must be set on each component", path)
+	}
+	for _, versionFile := range config.VersionFiles {
+		if versionFile.Path == "" {
+			return config, fmt.Errorf("invalid config %s: a version file has no path", path)
+		}
+	}
+	for _, urls := range [][]string{config.Notifications.Webhooks, config.Notifications.Slack, config.Notifications.Teams} {
+		for _, notifyURL := range urls {
+			if !ValidURL(notifyURL) {
+				return config, fmt.Errorf("invalid config %s: notification URL %s must be an http or https URL", path, notifyURL)
+			}
+		}
+	}
+	return config, nil
+}
+
+// ValidURL reports whether the URL is an absolute http or https URL
+func ValidURL(rawURL string) bool {
+	parsed, err := url.Parse(rawURL)
+	return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
@@ -0,0 +1,132 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "release.yaml")
+	err := os.WriteFile(path, []byte(content), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	assertTest := assert.New(t)
+	path := writeConfig(t, `
+tagTemplate: "{component}/v{version}"
+components:
+  - name: billing
+    changelog: services/billing/CHANGELOG.md
+  - name: auth
+    changelog: services/auth/CHANGELOG.md
+    path: services/auth
+    tagTemplate: "auth-{version}"
+`)
+	cfg, err := Load(path)
+	assertTest.NoError(err)
+	assertTest.Equal("{component}/v{version}", cfg.TagTemplate)
+	assertTest.Equal([]Component{
+		{Name: "billing", Changelog: "services/billing/CHANGELOG.md"},
+		{Name: "auth", Changelog: "services/auth/CHANGELOG.md", Path: "services/auth", TagTemplate: "auth-{version}"},
+	}, cfg.Components)
+}
+
+func TestLoadNotifications(t *testing.T) {
+	assertTest := assert.New(t)
+	cfg, err := Load(writeConfig(t, `
+notifications:
+  strict: true
+  webhooks:
+    - https://example.com/hook
+  slack:
+    - https://hooks.slack.com/services/T/B/X
+  teams:
+    - https://example.webhook.office.com/webhookb2/X
+`))
+	assertTest.NoError(err)
+	assertTest.Equal(Notifications{
+		Webhooks: []string{"https://example.com/hook"},
+		Slack:    []string{"https://hooks.slack.com/services/T/B/X"},
+		Teams:    []string{"https://example.webhook.office.com/webhookb2/X"},
+		Strict:   true,
+	}, cfg.Notifications)
+	assertTest.Empty(cfg.Components)
+
+	_, err = Load(writeConfig(t, "notifications:\n  slack:\n    - hooks.slack.com/services/T/B/X\n"))
+	assertTest.ErrorContains(err, "must be an http or https URL")
+}
+
+func TestLoadHooks(t *testing.T) {
+	cfg, err := Load(writeConfig(t, `
+hooks:
+  preValidate:
+    - make lint
+  preCreate:
+    - make build
+    - make package
+  postCreate:
+    - ./deploy.sh
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, Hooks{
+		PreValidate: []string{"make lint"},
+		PreCreate:   []string{"make build", "make package"},
+		PostCreate:  []string{"./deploy.sh"},
+	}, cfg.Hooks)
+}
+
+func TestLoadVersionFiles(t *testing.T) {
+	assertTest := assert.New(t)
+	cfg, err := Load(writeConfig(t, `
+versionFiles:
+  - path: package.json
+  - path: version.go
+    pattern: 'Version = "(.*)"'
+`))
+	assertTest.NoError(err)
+	assertTest.Equal([]VersionFile{{Path: "package.json"}, {Path: "version.go", Pattern: `Version = "(.*)"`}}, cfg.VersionFiles)
+
+	cfg, err = Load(writeConfig(t, `
+components:
+  - name: api
+    changelog: api/CHANGELOG.md
+    versionFiles:
+      - path: api/pyproject.toml
+        key: tool.poetry.version
+`))
+	assertTest.NoError(err)
+	assertTest.Equal([]VersionFile{{Path: "api/pyproject.toml", Key: "tool.poetry.version"}}, cfg.Components[0].VersionFiles)
+
+	_, err = Load(writeConfig(t, "versionFiles:\n  - path: package.json\ncomponents:\n  - name: api\n    changelog: CHANGELOG.md\n"))
+	assertTest.ErrorContains(err, "versionFiles must be set on each component")
+
+	_, err = Load(writeConfig(t, "versionFiles:\n  - key: version\n"))
+	assertTest.ErrorContains(err, "a version file has no path")
+}
+
+func TestLoadEmpty(t *testing.T) {
+	cfg, err := Load(writeConfig(t, ""))
+	assert.NoError(t, err)
+	assert.Empty(t, cfg.Components)
+}
+
+func TestLoadInvalid(t *testing.T) {
+	assertTest := assert.New(t)
+	_, err := Load(writeConfig(t, "components:\n  - name: billing\n    changlog: CHANGELOG.md\n"))
+	assertTest.ErrorContains(err, "changlog")
+
+	_, err = Load(writeConfig(t, "components:\n  - changelog: CHANGELOG.md\n"))
+	assertTest.ErrorContains(err, "has no name")
+
+	_, err = Load(writeConfig(t, "components:\n  - name: billing\n"))
+	assertTest.ErrorContains(err, "has no changelog")
+
+	_, err = Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	assertTest.Error(err)
+}
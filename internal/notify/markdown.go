@@ -0,0 +1,116 @@
+package notify
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+var headingRegex = regexp.MustCompile(`^#{1,6}\s+(.*?)\s*#*$`)
+var bulletRegex = regexp.MustCompile(`^(\s*)[*+-]\s+`)
+var boldRegex = regexp.MustCompile(`\*\*(.+?)\*\*|__(.+?)__`)
+var italicRegex = regexp.MustCompile(`\*([^*\s][^*]*?)\*`)
+var linkRegex = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+
+// boldMarker stands in for converted bold text until italics are converted
+const boldMarker = "\x00"
+
+// convertLines converts each line outside of fenced code blocks
+func convertLines(markdown string, convert func(line string) string) []string {
+	lines := strings.Split(strings.TrimSpace(markdown), "\n")
+	fenced := false
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			fenced = !fenced
+			continue
+		}
+		if !fenced {
+			lines[i] = convert(line)
+		}
+	}
+	return lines
+}
+
+// bold replaces the text of bold markdown with the replacement, $1 being the text
+func bold(line string, replacement string) string {
+	return boldRegex.ReplaceAllStringFunc(line, func(match string) string {
+		return strings.ReplaceAll(replacement, "$1", match[2:len(match)-2])
+	})
+}
+
+// SlackEscape escapes the characters Slack uses for links and mentions
+func SlackEscape(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
+
+// SlackMarkdown converts markdown to Slack mrkdwn, headings become bold lines and links use the <url|text> format
+func SlackMarkdown(markdown string) string {
+	return strings.Join(convertLines(markdown, func(line string) string {
+		line = SlackEscape(line)
+		if heading := headingRegex.FindStringSubmatch(line); heading != nil {
+			return "*" + boldRegex.ReplaceAllString(heading[1], "$1$2") + "*"
+		}
+		line = bulletRegex.ReplaceAllString(line, "$1• ")
+		line = bold(line, boldMarker+"$1"+boldMarker)
+		line = italicRegex.ReplaceAllString(line, "_${1}_")
+		line = strings.ReplaceAll(line, boldMarker, "*")
+		return linkRegex.ReplaceAllString(line, "<$2|$1>")
+	}), "\n")
+}
+
+// TeamsMarkdown converts markdown to the subset rendered by Teams message cards, headings become bold lines and lines
+// are separated by blank lines as single line breaks are ignored, apart from between list items
+func TeamsMarkdown(markdown string) string {
+	if strings.TrimSpace(markdown) == "" {
+		return ""
+	}
+	lines := convertLines(markdown, func(line string) string {
+		if heading := headingRegex.FindStringSubmatch(line); heading != nil {
+			return "**" + boldRegex.ReplaceAllString(heading[1], "$1$2") + "**"
+		}
+		return bulletRegex.ReplaceAllString(line, "$1- ")
+	})
+	var text strings.Builder
+	previous := ""
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if text.Len() > 0 {
+			if bulletRegex.MatchString(previous) && bulletRegex.MatchString(line) {
+				text.WriteString("\n")
+			} else {
+				text.WriteString("\n\n")
+			}
+		}
+		text.WriteString(line)
+		previous = line
+	}
+	return text.String()
+}
+
+// PlainText removes markdown formatting, links keep their url after the text
+func PlainText(markdown string) string {
+	return strings.Join(convertLines(markdown, func(line string) string {
+		if heading := headingRegex.FindStringSubmatch(line); heading != nil {
+			line = heading[1]
+		}
+		line = bulletRegex.ReplaceAllString(line, "$1- ")
+		line = bold(line, "$1")
+		line = italicRegex.ReplaceAllString(line, "$1")
+		return linkRegex.ReplaceAllString(line, "$1 ($2)")
+	}), "\n")
+}
+
+// truncate shortens text to the limit in bytes without splitting characters
+func truncate(text string, limit int) string {
+	if len(text) <= limit {
+		return text
+	}
+	const ellipsis = "…"
+	cut := limit - len(ellipsis)
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	return text[:cut] + ellipsis
+}
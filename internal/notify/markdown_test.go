@@ -0,0 +1,59 @@
+package notify
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+const notes = `### Added
+* **Bold** change with a [link](https://example.com/a?b=1&c=2)
+* *italic* change
+
+### Fixed
+- x < y
+
+` + "```\n# not a heading\n```"
+
+func TestSlackMarkdown(t *testing.T) {
+	assertTest := assert.New(t)
+	assertTest.Equal("*Added*\n"+
+		"• *Bold* change with a <https://example.com/a?b=1&amp;c=2|link>\n"+
+		"• _italic_ change\n"+
+		"\n"+
+		"*Fixed*\n"+
+		"• x &lt; y\n"+
+		"\n"+
+		"```\n# not a heading\n```", SlackMarkdown(notes))
+}
+
+func TestTeamsMarkdown(t *testing.T) {
+	assertTest := assert.New(t)
+	assertTest.Equal("**Added**\n\n"+
+		"- **Bold** change with a [link](https://example.com/a?b=1&c=2)\n"+
+		"- *italic* change\n\n"+
+		"**Fixed**\n\n"+
+		"- x < y\n\n"+
+		"```\n\n# not a heading\n\n```", TeamsMarkdown(notes))
+	assertTest.Equal("", TeamsMarkdown(" \n"))
+}
+
+func TestPlainText(t *testing.T) {
+	assertTest := assert.New(t)
+	assertTest.Equal("Added\n"+
+		"- Bold change with a link (https://example.com/a?b=1&c=2)\n"+
+		"- italic change\n"+
+		"\n"+
+		"Fixed\n"+
+		"- x < y\n"+
+		"\n"+
+		"```\n# not a heading\n```", PlainText(notes))
+}
+
+func TestTruncate(t *testing.T) {
+	assertTest := assert.New(t)
+	assertTest.Equal("short", truncate("short", 10))
+	truncated := truncate(strings.Repeat("é", 10), 8)
+	assertTest.Equal("éé…", truncated)
+	assertTest.LessOrEqual(len(truncated), 8)
+}
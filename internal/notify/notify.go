@@ -0,0 +1,58 @@
+// Package notify announces created releases to webhooks, Slack and Microsoft Teams
+package notify
+
+import (
+	"context"
+	"fmt"
+	"github.com/sanjP10/release/internal/tag"
+	"net/http"
+)
+
+// Release is announced to the notifiers once its tag is created
+type Release struct {
+	Tag  string
+	Repo string
+	// URL of the release page, empty when the provider has none
+	URL string
+	// Notes are the markdown changes of the released version
+	Notes string
+}
+
+// Notifier announces a release to a target
+type Notifier interface {
+	// Name of the target in error messages
+	Name() string
+	Notify(ctx context.Context, release Release) error
+}
+
+// NotifyAll announces the release to every notifier, the errors of the notifiers that failed are returned
+func NotifyAll(ctx context.Context, notifiers []Notifier, release Release) []error {
+	var errors []error
+	for _, notifier := range notifiers {
+		err := notifier.Notify(ctx, release)
+		if err != nil {
+			errors = append(errors, fmt.Errorf("%s: %w", notifier.Name(), err))
+		}
+	}
+	return errors
+}
+
+// post sends the payload as JSON, any status other than 2xx is an error
+func post(ctx context.Context, client *http.Client, url string, payload interface{}) error {
+	request, err := tag.NewJSONRequest(ctx, "POST", url, payload)
+	if err != nil {
+		return err
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return tag.ResponseError(resp)
+	}
+	return nil
+}
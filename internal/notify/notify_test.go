@@ -0,0 +1,85 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+var release = Release{
+	Tag:   "1.2.0",
+	Repo:  "owner/repo",
+	URL:   "https://github.com/owner/repo/releases/tag/1.2.0",
+	Notes: "### Added\n* **New** feature",
+}
+
+// receive starts a server recording the JSON posted to it and answering with the status
+func receive(t *testing.T, status int) (*httptest.Server, *map[string]interface{}) {
+	received := map[string]interface{}{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		body, _ := io.ReadAll(r.Body)
+		assert.NoError(t, json.Unmarshal(body, &received))
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte("invalid_payload"))
+	}))
+	t.Cleanup(server.Close)
+	return server, &received
+}
+
+func TestWebhook(t *testing.T) {
+	assertTest := assert.New(t)
+	server, received := receive(t, http.StatusNoContent)
+	err := Webhook{URL: server.URL}.Notify(context.Background(), release)
+	assertTest.NoError(err)
+	assertTest.Equal(map[string]interface{}{
+		"tag":   "1.2.0",
+		"repo":  "owner/repo",
+		"url":   "https://github.com/owner/repo/releases/tag/1.2.0",
+		"notes": "### Added\n* **New** feature",
+		"text":  "Added\n- New feature",
+	}, *received)
+}
+
+func TestSlack(t *testing.T) {
+	assertTest := assert.New(t)
+	server, received := receive(t, http.StatusOK)
+	err := Slack{URL: server.URL}.Notify(context.Background(), release)
+	assertTest.NoError(err)
+	assertTest.Equal("Released 1.2.0 of owner/repo", (*received)["text"])
+	blocks := (*received)["blocks"].([]interface{})
+	assertTest.Len(blocks, 2)
+	assertTest.Equal("*Released <https://github.com/owner/repo/releases/tag/1.2.0|1.2.0>* of owner/repo",
+		blocks[0].(map[string]interface{})["text"].(map[string]interface{})["text"])
+	assertTest.Equal("*Added*\n• *New* feature", blocks[1].(map[string]interface{})["text"].(map[string]interface{})["text"])
+}
+
+func TestTeams(t *testing.T) {
+	assertTest := assert.New(t)
+	server, received := receive(t, http.StatusOK)
+	err := Teams{URL: server.URL}.Notify(context.Background(), release)
+	assertTest.NoError(err)
+	assertTest.Equal("MessageCard", (*received)["@type"])
+	assertTest.Equal("Released 1.2.0 of owner/repo", (*received)["title"])
+	assertTest.Equal("**Added**\n\n- **New** feature", (*received)["text"])
+	actions := (*received)["potentialAction"].([]interface{})
+	assertTest.Equal("OpenUri", actions[0].(map[string]interface{})["@type"])
+}
+
+func TestNotifyAll(t *testing.T) {
+	assertTest := assert.New(t)
+	ok, _ := receive(t, http.StatusOK)
+	failing, _ := receive(t, http.StatusBadRequest)
+	errors := NotifyAll(context.Background(), []Notifier{
+		Webhook{URL: ok.URL},
+		Slack{URL: failing.URL},
+		Teams{URL: ok.URL},
+	}, release)
+	assertTest.Len(errors, 1)
+	assertTest.EqualError(errors[0], "slack: status 400: invalid_payload")
+}
@@ -0,0 +1,57 @@
+package notify
+
+import (
+	"context"
+	"net/http"
+)
+
+// slackTextLimit is the longest text of a Slack section block
+const slackTextLimit = 3000
+
+// SlackText is a text object of a Slack block
+type SlackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// SlackBlock is a section of a Slack message
+type SlackBlock struct {
+	Type string    `json:"type"`
+	Text SlackText `json:"text"`
+}
+
+// SlackMessage is the body posted to Slack incoming webhooks
+type SlackMessage struct {
+	// Text is shown in notifications and by clients without blocks
+	Text   string       `json:"text"`
+	Blocks []SlackBlock `json:"blocks"`
+}
+
+// Slack posts the release to a Slack incoming webhook
+type Slack struct {
+	URL    string
+	Client *http.Client
+}
+
+// Name of the target
+func (s Slack) Name() string { return "slack" }
+
+// Notify posts the release with its notes converted to Slack mrkdwn
+func (s Slack) Notify(ctx context.Context, release Release) error {
+	title := "Released " + release.Tag + " of " + release.Repo
+	heading := "*Released " + SlackEscape(release.Tag) + "* of " + SlackEscape(release.Repo)
+	if release.URL != "" {
+		heading = "*Released <" + release.URL + "|" + SlackEscape(release.Tag) + ">* of " + SlackEscape(release.Repo)
+	}
+	message := SlackMessage{
+		Text:   title,
+		Blocks: []SlackBlock{{Type: "section", Text: SlackText{Type: "mrkdwn", Text: heading}}},
+	}
+	if release.Notes != "" {
+		message.Blocks = append(message.Blocks, SlackBlock{
+			Type: "section",
+			Text: SlackText{Type: "mrkdwn", Text: truncate(SlackMarkdown(release.Notes), slackTextLimit)},
+		})
+	}
+	return post(ctx, s.Client, s.URL, message)
+}
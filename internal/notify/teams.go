@@ -0,0 +1,58 @@
+package notify
+
+import (
+	"context"
+	"net/http"
+)
+
+// TeamsTarget is a link opened by a card action
+type TeamsTarget struct {
+	OS  string `json:"os"`
+	URI string `json:"uri"`
+}
+
+// TeamsAction is a button of a message card
+type TeamsAction struct {
+	Type    string        `json:"@type"`
+	Name    string        `json:"name"`
+	Targets []TeamsTarget `json:"targets"`
+}
+
+// TeamsCard is the message card posted to Microsoft Teams connectors
+type TeamsCard struct {
+	Type            string        `json:"@type"`
+	Context         string        `json:"@context"`
+	Summary         string        `json:"summary"`
+	Title           string        `json:"title"`
+	Text            string        `json:"text,omitempty"`
+	PotentialAction []TeamsAction `json:"potentialAction,omitempty"`
+}
+
+// Teams posts the release to a Microsoft Teams connector
+type Teams struct {
+	URL    string
+	Client *http.Client
+}
+
+// Name of the target
+func (t Teams) Name() string { return "teams" }
+
+// Notify posts the release as a message card with its notes converted to the markdown Teams renders
+func (t Teams) Notify(ctx context.Context, release Release) error {
+	title := "Released " + release.Tag + " of " + release.Repo
+	card := TeamsCard{
+		Type:    "MessageCard",
+		Context: "https://schema.org/extensions",
+		Summary: title,
+		Title:   title,
+		Text:    TeamsMarkdown(release.Notes),
+	}
+	if release.URL != "" {
+		card.PotentialAction = []TeamsAction{{
+			Type:    "OpenUri",
+			Name:    "View release",
+			Targets: []TeamsTarget{{OS: "default", URI: release.URL}},
+		}}
+	}
+	return post(ctx, t.Client, t.URL, card)
+}
@@ -0,0 +1,37 @@
+package notify
+
+import (
+	"context"
+	"net/http"
+)
+
+// WebhookPayload is the JSON body posted to generic webhooks
+type WebhookPayload struct {
+	Tag  string `json:"tag"`
+	Repo string `json:"repo"`
+	URL  string `json:"url,omitempty"`
+	// Notes are the release notes as markdown
+	Notes string `json:"notes"`
+	// Text are the release notes as plain text
+	Text string `json:"text"`
+}
+
+// Webhook posts the release as JSON to any endpoint
+type Webhook struct {
+	URL    string
+	Client *http.Client
+}
+
+// Name of the target
+func (w Webhook) Name() string { return "webhook" }
+
+// Notify posts the release with its notes as markdown and plain text
+func (w Webhook) Notify(ctx context.Context, release Release) error {
+	return post(ctx, w.Client, w.URL, WebhookPayload{
+		Tag:   release.Tag,
+		Repo:  release.Repo,
+		URL:   release.URL,
+		Notes: release.Notes,
+		Text:  PlainText(release.Notes),
+	})
+}
@@ -0,0 +1,64 @@
+package tag
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// ChangeLister lists the files changed between an existing tag and the hash being released
+type ChangeLister interface {
+	// ChangedFiles lists the paths, relative to the root of the repository, changed between the base tag and Hash
+	ChangedFiles(base string) ([]string, error)
+}
+
+// ChangedUnder reports whether any of the files are inside the directory, the root directory "." holds every file.
+// Globs relative to the directory narrow the files that count as changes, a glob matching a directory matches the
+// files inside it
+func ChangedUnder(files []string, dir string, globs ...string) bool {
+	dir = strings.Trim(path.Clean("/"+strings.ReplaceAll(dir, "\\", "/")), "/")
+	for _, file := range files {
+		relative := file
+		if dir != "" {
+			if !strings.HasPrefix(file, dir+"/") {
+				continue
+			}
+			relative = strings.TrimPrefix(file, dir+"/")
+		}
+		if len(globs) == 0 || matchesGlob(relative, globs) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesGlob reports whether the file or one of the directories holding it matches a glob
+func matchesGlob(file string, globs []string) bool {
+	for _, glob := range globs {
+		for candidate := file; candidate != "."; candidate = path.Dir(candidate) {
+			if matched, _ := path.Match(glob, candidate); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// DecodeJSON reads a successful response into v, any other status is an error holding part of the body
+func DecodeJSON(resp *http.Response, v interface{}) error {
+	if resp.StatusCode != http.StatusOK {
+		return ResponseError(resp)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("unable to read response: %w", err)
+	}
+	err = json.Unmarshal(body, v)
+	if err != nil {
+		return fmt.Errorf("unexpected response: %w", err)
+	}
+	return nil
+}
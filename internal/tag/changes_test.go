@@ -0,0 +1,45 @@
+package tag
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestChangedUnder(t *testing.T) {
+	assertTest := assert.New(t)
+	files := []string{"services/billing/main.go", "README.md"}
+	assertTest.True(ChangedUnder(files, "services/billing"))
+	assertTest.True(ChangedUnder(files, "./services/billing/"))
+	assertTest.True(ChangedUnder(files, "."))
+	assertTest.False(ChangedUnder(files, "services/bill"))
+	assertTest.False(ChangedUnder(files, "services/auth"))
+	assertTest.False(ChangedUnder(nil, "."))
+}
+
+func TestDecodeJSON(t *testing.T) {
+	assertTest := assert.New(t)
+	value := map[string]string{}
+	resp := &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{"a":"b"}`))}
+	assertTest.NoError(DecodeJSON(resp, &value))
+	assertTest.Equal("b", value["a"])
+
+	resp = &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{"a"`))}
+	assertTest.ErrorContains(DecodeJSON(resp, &value), "unexpected response")
+
+	resp = &http.Response{StatusCode: http.StatusNotFound, Body: io.NopCloser(strings.NewReader(`not found`))}
+	assertTest.EqualError(DecodeJSON(resp, &value), "status 404: not found")
+}
+
+func TestChangedUnderGlobs(t *testing.T) {
+	assertTest := assert.New(t)
+	files := []string{"services/billing/docs/guide.md", "services/billing/go.mod"}
+	assertTest.True(ChangedUnder(files, "services/billing", "go.mod"))
+	assertTest.True(ChangedUnder(files, "services/billing", "docs"))
+	assertTest.True(ChangedUnder(files, "services/billing", "*.md", "docs/*"))
+	assertTest.False(ChangedUnder(files, "services/billing", "src", "*.go"))
+	assertTest.False(ChangedUnder(files, "services/auth", "go.mod"))
+	assertTest.True(ChangedUnder(files, ".", "services/*/go.mod"))
+}
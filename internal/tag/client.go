@@ -0,0 +1,112 @@
+package tag
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"time"
+)
+
+// ClientOptions configures the HTTP client used to call provider APIs
+type ClientOptions struct {
+	// Timeout limits each attempt of a request, waiting to retry is not included
+	Timeout            time.Duration
+	CACert             string
+	ClientCert         string
+	ClientKey          string
+	InsecureSkipVerify bool
+	// Retry applies to idempotent requests, creation requests are retried by the providers
+	Retry RetryPolicy
+}
+
+// NewHTTPClient creates an HTTP client honouring HTTPS_PROXY and NO_PROXY, with optional custom CA bundle and client certificate
+func NewHTTPClient(options ClientOptions) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	tlsConfig := &tls.Config{InsecureSkipVerify: options.InsecureSkipVerify}
+
+	if options.CACert != "" {
+		pem, err := os.ReadFile(options.CACert)
+		if err != nil {
+			return nil, err
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("no certificates found in " + options.CACert)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if options.ClientCert != "" || options.ClientKey != "" {
+		if options.ClientCert == "" || options.ClientKey == "" {
+			return nil, errors.New("a client certificate and key are both required")
+		}
+		certificate, err := tls.LoadX509KeyPair(options.ClientCert, options.ClientKey)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+	transport.TLSClientConfig = tlsConfig
+
+	var roundTripper http.RoundTripper = transport
+	if options.Timeout > 0 {
+		roundTripper = &timeoutTransport{base: roundTripper, timeout: options.Timeout}
+	}
+	if options.Retry.MaxRetries > 0 {
+		roundTripper = &retryTransport{base: roundTripper, policy: options.Retry}
+	}
+	return &http.Client{Transport: roundTripper}, nil
+}
+
+// timeoutTransport limits each attempt of a request including reading its body, so a retry delay such as waiting
+// for a rate limit to reset does not run into the timeout
+type timeoutTransport struct {
+	base    http.RoundTripper
+	timeout time.Duration
+}
+
+func (t *timeoutTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(request.Context(), t.timeout)
+	resp, err := t.base.RoundTrip(request.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// cancelBody releases the timeout of the attempt once the body is closed
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// HTTPClient returns the configured client, or a default client
+func (r *RepoProperties) HTTPClient() *http.Client {
+	if r.Client == nil {
+		return &http.Client{}
+	}
+	return r.Client
+}
+
+// RequestContext returns the context requests are made with, or a background context
+func (r *RepoProperties) RequestContext() context.Context {
+	if r.Context == nil {
+		return context.Background()
+	}
+	return r.Context
+}
@@ -0,0 +1,91 @@
+package tag
+
+import (
+	"encoding/pem"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNewHTTPClientCACert(t *testing.T) {
+	assertTest := assert.New(t)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, err := NewHTTPClient(ClientOptions{})
+	assertTest.NoError(err)
+	_, err = client.Get(server.URL)
+	assertTest.Error(err)
+
+	caPath := filepath.Join(t.TempDir(), "ca.pem")
+	caPem := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	assertTest.NoError(os.WriteFile(caPath, caPem, 0600))
+	client, err = NewHTTPClient(ClientOptions{CACert: caPath})
+	assertTest.NoError(err)
+	resp, err := client.Get(server.URL)
+	assertTest.NoError(err)
+	assertTest.Equal(http.StatusOK, resp.StatusCode)
+
+	client, err = NewHTTPClient(ClientOptions{InsecureSkipVerify: true})
+	assertTest.NoError(err)
+	resp, err = client.Get(server.URL)
+	assertTest.NoError(err)
+	assertTest.Equal(http.StatusOK, resp.StatusCode)
+}
+
+func TestNewHTTPClientTimeout(t *testing.T) {
+	assertTest := assert.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, err := NewHTTPClient(ClientOptions{Timeout: 50 * time.Millisecond})
+	assertTest.NoError(err)
+	_, err = client.Get(server.URL)
+	assertTest.Error(err)
+}
+
+func TestNewHTTPClientInvalidOptions(t *testing.T) {
+	assertTest := assert.New(t)
+	_, err := NewHTTPClient(ClientOptions{CACert: "missing.pem"})
+	assertTest.Error(err)
+
+	emptyPath := filepath.Join(t.TempDir(), "empty.pem")
+	assertTest.NoError(os.WriteFile(emptyPath, []byte("not a certificate"), 0600))
+	_, err = NewHTTPClient(ClientOptions{CACert: emptyPath})
+	assertTest.Error(err)
+
+	_, err = NewHTTPClient(ClientOptions{ClientCert: "cert.pem"})
+	assertTest.Error(err)
+}
+
+func TestNewHTTPClientTimeoutPerAttempt(t *testing.T) {
+	assertTest := assert.New(t)
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// the backoff before the retry is longer than the timeout of each attempt
+	client, err := NewHTTPClient(ClientOptions{Timeout: 50 * time.Millisecond, Retry: RetryPolicy{MaxRetries: 1, BaseDelay: 100 * time.Millisecond}})
+	assertTest.NoError(err)
+	resp, err := client.Get(server.URL)
+	assertTest.NoError(err)
+	assertTest.Equal(http.StatusOK, resp.StatusCode)
+	assertTest.NoError(resp.Body.Close())
+	assertTest.Equal(2, attempts)
+}
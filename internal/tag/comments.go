@@ -0,0 +1,37 @@
+package tag
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+// Commenter adds or updates comments on pull requests
+type Commenter interface {
+	// UpsertComment replaces the body of the comment on the pull request starting with the marker, or adds the body as
+	// a new comment when there is none. Comments quoting the marker further down are left alone
+	UpsertComment(pullRequest int, marker string, body string) error
+}
+
+// NewJSONRequest builds a request sending the value as JSON
+func NewJSONRequest(ctx context.Context, method string, url string, value interface{}) (*http.Request, error) {
+	body, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	request, err := http.NewRequestWithContext(ctx, method, url, bytes.NewBuffer(body))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	return request, nil
+}
+
+// CheckWriteResponse accepts a response to creating or updating a resource, any other status is an error
+func CheckWriteResponse(resp *http.Response) error {
+	if resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusCreated {
+		return nil
+	}
+	return ResponseError(resp)
+}
@@ -0,0 +1,25 @@
+package tag
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestNewJSONRequest(t *testing.T) {
+	assertTest := assert.New(t)
+	request, err := NewJSONRequest(context.Background(), "POST", "https://example.com/comments", map[string]string{"body": "preview"})
+	assertTest.NoError(err)
+	assertTest.Equal("application/json", request.Header.Get("Content-Type"))
+	body, _ := io.ReadAll(request.Body)
+	assertTest.JSONEq(`{"body":"preview"}`, string(body))
+}
+
+func TestCheckWriteResponse(t *testing.T) {
+	assertTest := assert.New(t)
+	assertTest.NoError(CheckWriteResponse(&http.Response{StatusCode: http.StatusCreated, Body: io.NopCloser(strings.NewReader(""))}))
+	assertTest.EqualError(CheckWriteResponse(&http.Response{StatusCode: http.StatusConflict, Body: io.NopCloser(strings.NewReader("conflict"))}), "status 409: conflict")
+}
@@ -0,0 +1,126 @@
+// Package faulttest injects network and server faults into provider requests so every provider can be checked
+// to fail cleanly instead of panicking or reporting success
+package faulttest
+
+import (
+	"github.com/sanjP10/release/internal/tag"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Provider is the behaviour of a tag provider under test
+type Provider interface {
+	ValidateTag() tag.ValidTagState
+	CreateTag() bool
+}
+
+// NewProvider builds the provider under test pointed at the host of the fault server
+type NewProvider func(host string, repoProperties tag.RepoProperties) Provider
+
+// Fault is a failure injected into a response
+type Fault struct {
+	Name    string
+	Handler http.HandlerFunc
+	// ValidateOnly faults return a successful status, creation trusts the status regardless of the body
+	ValidateOnly bool
+}
+
+// Timeout is how long the client waits before a slow response is abandoned
+const Timeout = 100 * time.Millisecond
+
+// Faults are the failures every provider should survive
+var Faults = []Fault{
+	{Name: "timeout", Handler: func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(10 * Timeout):
+		}
+	}},
+	{Name: "connection reset", Handler: func(w http.ResponseWriter, r *http.Request) {
+		hijacker, ok := w.(http.Hijacker)
+		if !ok {
+			panic("response writer does not support hijacking")
+		}
+		conn, _, err := hijacker.Hijack()
+		if err != nil {
+			panic(err)
+		}
+		conn.Close()
+	}},
+	{Name: "truncated json", Handler: reply(http.StatusOK, `{"object": {"sha": "ha`), ValidateOnly: true},
+	{Name: "empty body", Handler: reply(http.StatusOK, ""), ValidateOnly: true},
+	{Name: "unexpected json", Handler: reply(http.StatusOK, `["not", "an", "object"]`), ValidateOnly: true},
+	{Name: "html error page", Handler: reply(http.StatusBadGateway, "<html><body>Bad Gateway</body></html>")},
+	{Name: "server error", Handler: reply(http.StatusInternalServerError, "")},
+	{Name: "unexpected status", Handler: reply(http.StatusTeapot, "")},
+	{Name: "bad request without errors", Handler: reply(http.StatusBadRequest, "{}")},
+	{Name: "unprocessable entity without errors", Handler: reply(http.StatusUnprocessableEntity, `{"errors": []}`)},
+	{Name: "conflict with truncated json", Handler: reply(http.StatusConflict, `{"message": `)},
+	{Name: "truncated body", ValidateOnly: true, Handler: func(w http.ResponseWriter, r *http.Request) {
+		// the declared length is never written so reading the body fails part way
+		w.Header().Set("Content-Length", "100")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"object": `))
+	}},
+}
+
+func reply(status int, body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}
+}
+
+// Run checks the provider against every fault, first while validating the tag and then while creating it
+// after validation finds no tag, the provider must not panic or report success
+func Run(t *testing.T, newProvider NewProvider) {
+	RunWithMissingTag(t, newProvider, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+}
+
+// RunWithMissingTag is Run for providers that report a missing tag with missingTag rather than a 404
+func RunWithMissingTag(t *testing.T, newProvider NewProvider, missingTag http.HandlerFunc) {
+	for _, fault := range Faults {
+		fault := fault
+		t.Run("validate/"+fault.Name, func(t *testing.T) {
+			provider := start(t, newProvider, fault.Handler)
+			state := provider.ValidateTag()
+			if state.TagDoesntExist || state.TagExistsWithProvidedHash || !state.Unknown {
+				t.Errorf("validating tag reported %+v for %s", state, fault.Name)
+			}
+		})
+		if fault.ValidateOnly {
+			continue
+		}
+		t.Run("create/"+fault.Name, func(t *testing.T) {
+			provider := start(t, newProvider, func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet {
+					missingTag(w, r)
+					return
+				}
+				fault.Handler(w, r)
+			})
+			if provider.CreateTag() {
+				t.Errorf("creating tag succeeded for %s", fault.Name)
+			}
+		})
+	}
+}
+
+func start(t *testing.T, newProvider NewProvider, handler http.HandlerFunc) Provider {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	repoProperties := tag.RepoProperties{
+		Password: "password",
+		Tag:      "tag",
+		Hash:     "hash",
+		Body:     "hello",
+		Client:   &http.Client{Timeout: Timeout},
+	}
+	return newProvider(strings.TrimSuffix(server.URL, "/"), repoProperties)
+}
@@ -0,0 +1,43 @@
+package tag
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	urllib "net/url"
+	"strings"
+)
+
+// ErrFileNotFound is returned by a FileReader when the file does not exist at the ref
+var ErrFileNotFound = errors.New("file not found")
+
+// FileReader reads files of the repository at a branch, tag or commit
+type FileReader interface {
+	// ReadFile reads the file, relative to the root of the repository, at the ref
+	ReadFile(ref string, path string) (string, error)
+}
+
+// EscapePath escapes each segment of a slash separated path for use in a URL
+func EscapePath(path string) string {
+	segments := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	for i, segment := range segments {
+		segments[i] = urllib.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// ReadFileResponse reads the raw contents of a file from a successful response, 404 is ErrFileNotFound
+func ReadFileResponse(resp *http.Response) (string, error) {
+	switch resp.StatusCode {
+	case http.StatusOK:
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return "", fmt.Errorf("unable to read response: %w", err)
+		}
+		return string(body), nil
+	case http.StatusNotFound:
+		return "", ErrFileNotFound
+	}
+	return "", ResponseError(resp)
+}
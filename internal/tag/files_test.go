@@ -0,0 +1,26 @@
+package tag
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestEscapePath(t *testing.T) {
+	assert.Equal(t, "services/my%20service/CHANGELOG.md", EscapePath("/services/my service/CHANGELOG.md"))
+}
+
+func TestReadFileResponse(t *testing.T) {
+	assertTest := assert.New(t)
+	contents, err := ReadFileResponse(&http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("## 1.0.0"))})
+	assertTest.NoError(err)
+	assertTest.Equal("## 1.0.0", contents)
+
+	_, err = ReadFileResponse(&http.Response{StatusCode: http.StatusNotFound, Body: io.NopCloser(strings.NewReader(""))})
+	assertTest.ErrorIs(err, ErrFileNotFound)
+
+	_, err = ReadFileResponse(&http.Response{StatusCode: http.StatusForbidden, Body: io.NopCloser(strings.NewReader("forbidden"))})
+	assertTest.EqualError(err, "status 403: forbidden")
+}
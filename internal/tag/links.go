@@ -0,0 +1,7 @@
+package tag
+
+// ReleaseLinker links to the web page of a created tag
+type ReleaseLinker interface {
+	// ReleaseURL is the page of the release of Tag, or of the tag when the provider has no releases
+	ReleaseURL() string
+}
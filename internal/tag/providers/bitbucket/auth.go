@@ -0,0 +1,30 @@
+package bitbucket
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Authentication types for the Bitbucket API
+const (
+	AuthBasic  = "basic"
+	AuthBearer = "bearer"
+)
+
+// ValidAuthType checks the authentication type from the cli is supported
+func ValidAuthType(authType string) bool {
+	switch strings.ToLower(authType) {
+	case "", AuthBasic, AuthBearer:
+		return true
+	}
+	return false
+}
+
+// authorize sets the credentials of the authentication type on the request
+func (r *Properties) authorize(request *http.Request) {
+	if strings.ToLower(r.AuthType) == AuthBearer {
+		request.Header.Set("Authorization", "Bearer "+r.Password)
+		return
+	}
+	request.SetBasicAuth(r.Username, r.Password)
+}
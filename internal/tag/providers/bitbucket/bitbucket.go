@@ -7,8 +7,8 @@ import (
 	"github.com/sanjP10/release/internal/tag"
 	"io/ioutil"
 	"net/http"
+	urllib "net/url"
 	"os"
-	"strings"
 )
 
 // Target Structure of bitbucket tag target
@@ -37,8 +37,11 @@ type Error struct {
 type Properties struct {
 	tag.RepoProperties
 	Username string
-	Repo     string
-	Host     string
+	// Repo is workspace/repo on Bitbucket Cloud, project/repo or ~user/repo for personal repos on Bitbucket Server
+	Repo string
+	Host string
+	// AuthType is basic (default) with Username and Password, or bearer with Password as an HTTP access token
+	AuthType string
 }
 
 // ServerTag response
@@ -60,41 +63,38 @@ type ServerTagBody struct {
 
 // ValidateTag checks a tag does not exist or has the same hash
 func (r *Properties) ValidateTag() tag.ValidTagState {
-	isCloud := true // Using bitbucket cloud offering otherwise self-hosted
+	if r.Host != "" {
+		// self-hosted
+		return r.validateServerTag()
+	}
 	// Check tag exists, if 404 gd, 403 auth error, 200 exists and check hash is the same
 	validTag := tag.ValidTagState{TagDoesntExist: false, TagExistsWithProvidedHash: false}
-	url := ""
-	if r.Host == "" {
-		url = fmt.Sprintf("https://api.bitbucket.org/2.0/repositories/%s/refs/tags/%s", r.Repo, r.Tag)
-	} else {
-		isCloud = false
-		repoDetails := strings.Split(r.Repo, "/")
-		url = fmt.Sprintf("%s/rest/api/1.0/projects/%s/repos/%s/tags/%s", r.Host, repoDetails[0], repoDetails[1], r.Tag)
-	}
-	request, err := http.NewRequest("GET", url, nil)
+	url := fmt.Sprintf("https://api.bitbucket.org/2.0/repositories/%s/refs/tags/%s", r.Repo, urllib.PathEscape(r.Tag))
+	request, err := http.NewRequestWithContext(r.RequestContext(), "GET", url, nil)
 	if err != nil {
 		fmt.Println("Error validate tag request")
 	}
 	if request == nil {
-		_, err := os.Stderr.WriteString("Error creating request\n")
-		if err != nil {
+		_, errorWriting := os.Stderr.WriteString("Error creating request\n")
+		if errorWriting != nil {
 			panic("Cannot write to stderr")
 		}
-		return validTag
+		return tag.UnknownTagState(err)
 	}
-	request.SetBasicAuth(r.Username, r.Password)
-	client := &http.Client{}
+	r.authorize(request)
+	client := r.HTTPClient()
 	resp, err := client.Do(request)
 	if err != nil {
 		fmt.Println("Error validate tag request")
 	}
 	if resp == nil {
-		_, err := os.Stderr.WriteString("Error getting response\n")
-		if err != nil {
+		_, errorWriting := os.Stderr.WriteString("Error getting response\n")
+		if errorWriting != nil {
 			panic("Cannot write to stderr")
 		}
-		return validTag
+		return tag.UnknownTagState(err)
 	}
+	defer resp.Body.Close()
 	switch resp.StatusCode {
 	case http.StatusNotFound:
 		validTag.TagDoesntExist = true
@@ -103,67 +103,95 @@ func (r *Properties) ValidateTag() tag.ValidTagState {
 		if err != nil {
 			panic("Cannot write to stderr")
 		}
+		validTag = tag.UnexpectedResponse(resp)
 	case http.StatusOK:
-		validTag.TagExistsWithProvidedHash = checkResponse(resp, r.Hash, isCloud)
+		existsWithProvidedHash, err := checkResponse(resp, r.Hash)
+		if err != nil {
+			return tag.UnknownTagState(err)
+		}
+		validTag.TagExistsWithProvidedHash = existsWithProvidedHash
+	default:
+		validTag = tag.UnexpectedResponse(resp)
 	}
 	return validTag
 }
 
 // CreateTag creates a bitbucket tag
 func (r *Properties) CreateTag() bool {
-	createTag := false
-	validTagState := r.ValidateTag()
-	if validTagState.TagExistsWithProvidedHash {
-		createTag = true
-	} else if validTagState.TagDoesntExist {
-		isCloud := true // Using bitbucket cloud offering otherwise self-hosted
-		url := ""
-		if r.Host == "" {
-			url = fmt.Sprintf("https://api.bitbucket.org/2.0/repositories/%s/refs/tags", r.Repo)
-		} else {
-			isCloud = false
-			repoDetails := strings.Split(r.Repo, "/")
-			url = fmt.Sprintf("%s/rest/api/1.0/projects/%s/repos/%s/tags", r.Host, repoDetails[0], repoDetails[1])
+	return r.CreateWithRetry(r.ValidateTag, r.createTagRequest, r.handleCreateResponse, nil)
+}
+
+func (r *Properties) createTagRequest() (*http.Response, error) {
+	isCloud := true // Using bitbucket cloud offering otherwise self-hosted
+	url := ""
+	if r.Host == "" {
+		url = fmt.Sprintf("https://api.bitbucket.org/2.0/repositories/%s/refs/tags", r.Repo)
+	} else {
+		isCloud = false
+		repoPath, err := ServerRepoPath(r.Repo)
+		if err != nil {
+			fmt.Println("Error creating tag request", err)
+			return nil, err
 		}
+		url = fmt.Sprintf("%s/rest/api/1.0/%s/tags", r.Host, repoPath)
+	}
 
-		jsonBody := createBody(r, isCloud)
+	jsonBody := createBody(r, isCloud)
+
+	request, err := http.NewRequestWithContext(r.RequestContext(), "POST", url, bytes.NewBuffer(jsonBody))
+	if err != nil {
+		fmt.Println("Error creating tag request", err)
+		return nil, err
+	}
+	request.Header.Add("Content-Type", "application/json")
+	r.authorize(request)
+	client := r.HTTPClient()
+	return client.Do(request)
+}
+
+func (r *Properties) handleCreateResponse(resp *http.Response, err error) bool {
+	createTag := false
+	if err != nil {
+		fmt.Println("Error creating tag", err)
+	}
+	if resp == nil {
+		_, errorWriting := os.Stderr.WriteString("Error getting response\n")
+		if errorWriting != nil {
+			panic("Cannot write to stderr")
+		}
+		return r.FailRequest(err)
+	}
+	defer resp.Body.Close()
 
-		request, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonBody))
+	switch resp.StatusCode {
+	case http.StatusUnauthorized, http.StatusNotFound:
+		_, err := os.Stderr.WriteString("Unauthorised, please check credentials\n")
 		if err != nil {
-			fmt.Println("Error creating tag request", err)
+			panic("Cannot write to stderr")
 		}
-		request.Header.Add("Content-Type", "application/json")
-		request.SetBasicAuth(r.Username, r.Password)
-		client := &http.Client{}
-		resp, err := client.Do(request)
+		r.Fail(resp.StatusCode, "unauthorised, please check credentials")
+	case http.StatusOK, http.StatusCreated:
+		createTag = true
+	case http.StatusBadRequest:
+		res := BadResponse{}
+		body, err := ioutil.ReadAll(resp.Body)
 		if err != nil {
-			fmt.Println("Error creating tag", err)
+			fmt.Println("Error reading body of error response")
 		}
-
-		switch resp.StatusCode {
-		case http.StatusUnauthorized, http.StatusNotFound:
-			_, err := os.Stderr.WriteString("Unauthorised, please check credentials\n")
-			if err != nil {
-				panic("Cannot write to stderr")
-			}
-		case http.StatusOK, http.StatusCreated:
-			createTag = true
-		case http.StatusBadRequest:
-			res := BadResponse{}
-			body, err := ioutil.ReadAll(resp.Body)
-			if err != nil {
-				fmt.Println("Error reading body of error response")
-			}
-			err = json.Unmarshal(body, &res)
-			if err != nil {
-				fmt.Println("Error unmarshalling response")
-			}
-			_, errorWriting := os.Stderr.WriteString(res.Error.Message)
-			if errorWriting != nil {
-				panic("Cannot write to stderr")
-			}
+		err = json.Unmarshal(body, &res)
+		if err != nil {
+			fmt.Println("Error unmarshalling response")
+		}
+		_, errorWriting := os.Stderr.WriteString(res.Error.Message + "\n")
+		if errorWriting != nil {
+			panic("Cannot write to stderr")
+		}
+		r.Fail(resp.StatusCode, res.Error.Message)
+	default:
+		_, errorWriting := os.Stderr.WriteString("Error creating tag, " + r.FailResponse(resp).Error() + "\n")
+		if errorWriting != nil {
+			panic("Cannot write to stderr")
 		}
-
 	}
 	return createTag
 }
@@ -189,30 +217,21 @@ func createBody(r *Properties, isCloud bool) []byte {
 	return jsonBody
 }
 
-func checkResponse(resp *http.Response, hash string, isCloud bool) bool {
+func checkResponse(resp *http.Response, hash string) (bool, error) {
 	existsWithProvidedHash := false
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		fmt.Println("Error reading body of tag response")
+		return existsWithProvidedHash, fmt.Errorf("unable to read tag response: %w", err)
 	}
-	if isCloud {
-		res := Tag{}
-		err = json.Unmarshal(body, &res)
-		if err != nil {
-			fmt.Println("Error unmarshalling body")
-		}
-		if hash == res.Target.Hash {
-			existsWithProvidedHash = true
-		}
-	} else {
-		res := ServerTag{}
-		err = json.Unmarshal(body, &res)
-		if err != nil {
-			fmt.Println("Error unmarshalling body")
-		}
-		if hash == res.LatestCommit {
-			existsWithProvidedHash = true
-		}
+	res := Tag{}
+	err = json.Unmarshal(body, &res)
+	if err != nil {
+		fmt.Println("Error unmarshalling body")
+		return existsWithProvidedHash, fmt.Errorf("unexpected tag response: %w", err)
+	}
+	if hash == res.Target.Hash {
+		existsWithProvidedHash = true
 	}
-	return existsWithProvidedHash
+	return existsWithProvidedHash, nil
 }
@@ -2,6 +2,7 @@ package bitbucket
 
 import (
 	"github.com/sanjP10/release/internal/tag"
+	"github.com/sanjP10/release/internal/tag/faulttest"
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/h2non/gock.v1"
 	"net/http"
@@ -150,8 +151,10 @@ func TestCreateTagSuccessfulWithHostOverride(t *testing.T) {
 	defer gock.Off() // Flush pending mocks after test execution
 
 	gock.New("https://api.personal-bitbucket.com").
-		Get("/rest/api/1.0/projects/project/repos/repo/tags/tag").
-		Reply(http.StatusNotFound)
+		Get("/rest/api/1.0/projects/project/repos/repo/tags").
+		MatchParam("filterText", "tag").
+		Reply(http.StatusOK).
+		JSON(ServerTagPage{IsLastPage: true})
 
 	gock.New("https://api.personal-bitbucket.com").
 		Post("/rest/api/1.0/projects/project/repos/repo/tags").
@@ -168,9 +171,10 @@ func TestTagExistingSelfHosted(t *testing.T) {
 	defer gock.Off() // Flush pending mocks after test execution
 
 	gock.New("https://api.personal-bitbucket.com").
-		Get("/rest/api/1.0/projects/project/repos/repo/tags/tag").
+		Get("/rest/api/1.0/projects/project/repos/repo/tags").
+		MatchParam("filterText", "tag").
 		Reply(http.StatusOK).
-		JSON(response)
+		JSON(ServerTagPage{Values: []ServerTag{response}, IsLastPage: true})
 
 	assertTest := assert.New(t)
 	repo := Properties{Username: "username", Repo: "project/repo", Host: "https://api.personal-bitbucket.com", RepoProperties: tag.RepoProperties{Password: "password", Tag: "tag", Hash: "hash", Body: "hello"}}
@@ -207,6 +211,7 @@ func TestCreateTagError(t *testing.T) {
 	assertTest := assert.New(t)
 	repo := Properties{Username: "username", Repo: "repo", Host: "", RepoProperties: tag.RepoProperties{Password: "password", Tag: "test", Hash: "hash", Body: "hello"}}
 	assertTest.False(repo.CreateTag())
+	assertTest.Equal(tag.CreateFailure{StatusCode: http.StatusBadRequest, Message: "tag \"test\" already exists"}, repo.Failure())
 }
 
 func TestCreateTagOtherError(t *testing.T) {
@@ -239,3 +244,136 @@ func TestCreateTagOtherErrorResponse(t *testing.T) {
 	repo := Properties{Username: "username", Repo: "repo", Host: "", RepoProperties: tag.RepoProperties{Password: "password", Tag: "tag", Hash: "hash", Body: "hello"}}
 	assertTest.False(repo.CreateTag())
 }
+
+func TestProviderFaults(t *testing.T) {
+	noTags := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"values": [], "isLastPage": true}`))
+	}
+	faulttest.RunWithMissingTag(t, func(host string, repoProperties tag.RepoProperties) faulttest.Provider {
+		return &Properties{Username: "username", Repo: "project/repo", Host: host, RepoProperties: repoProperties}
+	}, noTags)
+}
+
+func TestInvalidServerRepo(t *testing.T) {
+	assertTest := assert.New(t)
+	for _, repoName := range []string{"repo", "project/", "project/repo/extra"} {
+		repo := Properties{Username: "username", Repo: repoName, Host: "https://api.personal-bitbucket.com", RepoProperties: tag.RepoProperties{Password: "password", Tag: "tag", Hash: "hash", Body: "hello"}}
+		results := repo.ValidateTag()
+		assertTest.False(results.TagDoesntExist)
+		assertTest.False(results.TagExistsWithProvidedHash)
+		assertTest.False(repo.CreateTag())
+	}
+}
+
+func TestValidateTagRateLimited(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	gock.New("https://api.bitbucket.org").
+		Get("/2.0/repositories/repo/refs/tags/tag").
+		Reply(http.StatusTooManyRequests)
+	assertTest := assert.New(t)
+	// Testing a 429 is reported as unknown rather than an existing tag
+	repo := Properties{Username: "username", Repo: "repo", Host: "", RepoProperties: tag.RepoProperties{Password: "password", Tag: "tag", Hash: "hash"}}
+	results := repo.ValidateTag()
+	assertTest.False(results.TagDoesntExist)
+	assertTest.True(results.Unknown)
+	assertTest.Equal(http.StatusTooManyRequests, results.StatusCode)
+}
+
+func TestValidateTagSelfHostedPaginated(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	// a prefix match on the first page, the exact tag on the second
+	gock.New("https://api.personal-bitbucket.com").
+		Get("/rest/api/1.0/users/user/repos/repo/tags").
+		MatchParam("filterText", "^1.0.0$").
+		MatchParam("start", "^0$").
+		Reply(http.StatusOK).
+		JSON(ServerTagPage{Values: []ServerTag{{DisplayID: "1.0.0-rc1", LatestCommit: "other"}}, NextPageStart: 1})
+	gock.New("https://api.personal-bitbucket.com").
+		Get("/rest/api/1.0/users/user/repos/repo/tags").
+		MatchParam("start", "^1$").
+		Reply(http.StatusOK).
+		JSON(ServerTagPage{Values: []ServerTag{{DisplayID: "1.0.0", LatestCommit: "hash"}}, IsLastPage: true})
+	assertTest := assert.New(t)
+	repo := Properties{Username: "username", Repo: "~user/repo", Host: "https://api.personal-bitbucket.com", RepoProperties: tag.RepoProperties{Password: "password", Tag: "1.0.0", Hash: "hash"}}
+	results := repo.ValidateTag()
+	assertTest.False(results.TagDoesntExist)
+	assertTest.True(results.TagExistsWithProvidedHash)
+	assertTest.True(gock.IsDone())
+}
+
+func TestValidateTagSelfHostedRepoNotFound(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	gock.New("https://api.personal-bitbucket.com").
+		Get("/rest/api/1.0/projects/project/repos/repo/tags").
+		Reply(http.StatusNotFound)
+	assertTest := assert.New(t)
+	// a missing repo is not a missing tag
+	repo := Properties{Username: "username", Repo: "project/repo", Host: "https://api.personal-bitbucket.com", RepoProperties: tag.RepoProperties{Password: "password", Tag: "tag", Hash: "hash"}}
+	results := repo.ValidateTag()
+	assertTest.False(results.TagDoesntExist)
+	assertTest.True(results.Unknown)
+}
+
+func TestCreateTagSelfHostedBearer(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	gock.New("https://api.personal-bitbucket.com").
+		Get("/rest/api/1.0/users/user/repos/repo/tags").
+		MatchHeader("Authorization", "^Bearer token$").
+		Reply(http.StatusOK).
+		JSON(ServerTagPage{IsLastPage: true})
+	gock.New("https://api.personal-bitbucket.com").
+		Post("/rest/api/1.0/users/user/repos/repo/tags").
+		MatchHeader("Authorization", "^Bearer token$").
+		Reply(http.StatusOK).
+		JSON(ServerTag{DisplayID: "tag", LatestCommit: "hash"})
+	assertTest := assert.New(t)
+	repo := Properties{Repo: "~user/repo", Host: "https://api.personal-bitbucket.com", AuthType: AuthBearer, RepoProperties: tag.RepoProperties{Password: "token", Tag: "tag", Hash: "hash", Body: "hello"}}
+	assertTest.True(repo.CreateTag())
+	assertTest.True(gock.IsDone())
+}
+
+func TestServerRepoPath(t *testing.T) {
+	assertTest := assert.New(t)
+	repoPath, err := ServerRepoPath("PROJ/repo")
+	assertTest.NoError(err)
+	assertTest.Equal("projects/PROJ/repos/repo", repoPath)
+
+	repoPath, err = ServerRepoPath("~user/repo")
+	assertTest.NoError(err)
+	assertTest.Equal("users/user/repos/repo", repoPath)
+
+	for _, repo := range []string{"repo", "/repo", "project/", "~/repo", "a/b/c"} {
+		_, err = ServerRepoPath(repo)
+		assertTest.Error(err, repo)
+	}
+}
+
+func TestReleaseURL(t *testing.T) {
+	assertTest := assert.New(t)
+	properties := Properties{Repo: "workspace/repo", RepoProperties: tag.RepoProperties{Tag: "1.0.0"}}
+	assertTest.Equal("https://bitbucket.org/workspace/repo/src/1.0.0", properties.ReleaseURL())
+	properties.Host = "https://bitbucket.example.com"
+	properties.Repo = "PROJ/repo"
+	assertTest.Equal("https://bitbucket.example.com/projects/PROJ/repos/repo/browse?at=refs%2Ftags%2F1.0.0", properties.ReleaseURL())
+}
+
+func TestValidateTagWithSlash(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	gock.New("https://api.bitbucket.org").
+		Get("/2.0/repositories/repo/refs/tags/billing/1.3.0").
+		AddMatcher(func(req *http.Request, _ *gock.Request) (bool, error) {
+			return req.URL.EscapedPath() == "/2.0/repositories/repo/refs/tags/billing%2F1.3.0", nil
+		}).
+		Reply(http.StatusOK).
+		JSON(map[string]interface{}{"target": map[string]string{"hash": "hash"}})
+	assertTest := assert.New(t)
+	repo := Properties{Username: "username", Repo: "repo", RepoProperties: tag.RepoProperties{Password: "password", Tag: "billing/1.3.0", Hash: "hash"}}
+	results := repo.ValidateTag()
+	assertTest.True(results.TagExistsWithProvidedHash)
+}
@@ -0,0 +1,110 @@
+package bitbucket
+
+import (
+	"fmt"
+	"github.com/sanjP10/release/internal/tag"
+	"strings"
+)
+
+// CommentContent is the markdown of a Bitbucket Cloud comment
+type CommentContent struct {
+	Raw string `json:"raw"`
+}
+
+// Comment is a comment on a Bitbucket Cloud pull request
+type Comment struct {
+	ID      int64          `json:"id,omitempty"`
+	Content CommentContent `json:"content"`
+	Deleted bool           `json:"deleted,omitempty"`
+}
+
+// CommentPage paged response of Bitbucket Cloud pull request comments
+type CommentPage struct {
+	Values []Comment `json:"values"`
+	Next   string    `json:"next"`
+}
+
+// ServerComment is a comment on a Bitbucket Server pull request, the version is required to update it
+type ServerComment struct {
+	ID      int64  `json:"id,omitempty"`
+	Text    string `json:"text"`
+	Version *int   `json:"version,omitempty"`
+}
+
+// ServerActivity is an event on a Bitbucket Server pull request, such as a comment
+type ServerActivity struct {
+	Action  string         `json:"action"`
+	Comment *ServerComment `json:"comment"`
+}
+
+// ServerActivityPage paged response of Bitbucket Server pull request activities
+type ServerActivityPage struct {
+	Values        []ServerActivity `json:"values"`
+	IsLastPage    bool             `json:"isLastPage"`
+	NextPageStart int              `json:"nextPageStart"`
+}
+
+// UpsertComment updates the comment on the pull request starting with the marker, or adds a new comment
+func (r *Properties) UpsertComment(pullRequest int, marker string, body string) error {
+	if r.Host != "" {
+		return r.upsertServerComment(pullRequest, marker, body)
+	}
+	commentsURL := fmt.Sprintf("https://api.bitbucket.org/2.0/repositories/%s/pullrequests/%d/comments", r.Repo, pullRequest)
+	url := commentsURL + "?pagelen=100"
+	for url != "" {
+		page := CommentPage{}
+		err := r.getPage(url, &page)
+		if err != nil {
+			return fmt.Errorf("unable to list comments of pull request, %w", err)
+		}
+		for _, comment := range page.Values {
+			if !comment.Deleted && strings.HasPrefix(comment.Content.Raw, marker) {
+				return r.send("PUT", fmt.Sprintf("%s/%d", commentsURL, comment.ID), Comment{Content: CommentContent{Raw: body}})
+			}
+		}
+		url = page.Next
+	}
+	return r.send("POST", commentsURL, Comment{Content: CommentContent{Raw: body}})
+}
+
+func (r *Properties) upsertServerComment(pullRequest int, marker string, body string) error {
+	repoPath, err := ServerRepoPath(r.Repo)
+	if err != nil {
+		return err
+	}
+	pullRequestURL := fmt.Sprintf("%s/rest/api/1.0/%s/pull-requests/%d", r.Host, repoPath, pullRequest)
+	start := 0
+	for {
+		page := ServerActivityPage{}
+		err = r.getPage(fmt.Sprintf("%s/activities?start=%d&limit=%d", pullRequestURL, start, serverPageLimit), &page)
+		if err != nil {
+			return fmt.Errorf("unable to list comments of pull request, %w", err)
+		}
+		for _, activity := range page.Values {
+			comment := activity.Comment
+			if activity.Action == "COMMENTED" && comment != nil && strings.HasPrefix(comment.Text, marker) {
+				return r.send("PUT", fmt.Sprintf("%s/comments/%d", pullRequestURL, comment.ID), ServerComment{Text: body, Version: comment.Version})
+			}
+		}
+		if page.IsLastPage || page.NextPageStart <= start {
+			break
+		}
+		start = page.NextPageStart
+	}
+	return r.send("POST", pullRequestURL+"/comments", ServerComment{Text: body})
+}
+
+// send creates or updates a resource with the value as JSON
+func (r *Properties) send(method string, url string, value interface{}) error {
+	request, err := tag.NewJSONRequest(r.RequestContext(), method, url, value)
+	if err != nil {
+		return err
+	}
+	r.authorize(request)
+	resp, err := r.HTTPClient().Do(request)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return tag.CheckWriteResponse(resp)
+}
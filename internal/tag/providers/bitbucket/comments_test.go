@@ -0,0 +1,49 @@
+package bitbucket
+
+import (
+	"github.com/sanjP10/release/internal/tag"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+	"net/http"
+	"testing"
+)
+
+func TestUpsertCommentCloud(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	gock.New("https://api.bitbucket.org").
+		Get("/2.0/repositories/owner/repo/pullrequests/4/comments").
+		Reply(http.StatusOK).
+		JSON(CommentPage{Values: []Comment{
+			{ID: 1, Content: CommentContent{Raw: "<!-- marker -->"}, Deleted: true},
+			{ID: 2, Content: CommentContent{Raw: "> <!-- marker -->\nquoted"}},
+		}})
+	gock.New("https://api.bitbucket.org").
+		Post("/2.0/repositories/owner/repo/pullrequests/4/comments").
+		JSON(Comment{Content: CommentContent{Raw: "<!-- marker -->\nnew"}}).
+		Reply(http.StatusCreated)
+	repo := Properties{Username: "user", Repo: "owner/repo", RepoProperties: tag.RepoProperties{Password: "token"}}
+	assert.NoError(t, repo.UpsertComment(4, "<!-- marker -->", "<!-- marker -->\nnew"))
+	assert.True(t, gock.IsDone())
+}
+
+func TestUpsertCommentServer(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	version := 3
+	gock.New("https://bitbucket.example.com").
+		Get("/rest/api/1.0/projects/PROJ/repos/repo/pull-requests/4/activities").
+		Reply(http.StatusOK).
+		JSON(ServerActivityPage{IsLastPage: true, Values: []ServerActivity{
+			{Action: "APPROVED"},
+			{Action: "COMMENTED", Comment: &ServerComment{ID: 7, Text: "> <!-- marker -->\nquoted", Version: &version}},
+			{Action: "COMMENTED", Comment: &ServerComment{ID: 8, Text: "<!-- marker -->\nold", Version: &version}},
+		}})
+	gock.New("https://bitbucket.example.com").
+		Put("/rest/api/1.0/projects/PROJ/repos/repo/pull-requests/4/comments/8").
+		JSON(ServerComment{Text: "<!-- marker -->\nnew", Version: &version}).
+		Reply(http.StatusOK)
+	repo := Properties{Username: "user", Repo: "PROJ/repo", Host: "https://bitbucket.example.com", RepoProperties: tag.RepoProperties{Password: "token"}}
+	assert.NoError(t, repo.UpsertComment(4, "<!-- marker -->", "<!-- marker -->\nnew"))
+	assert.True(t, gock.IsDone())
+}
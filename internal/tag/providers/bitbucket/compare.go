@@ -0,0 +1,125 @@
+package bitbucket
+
+import (
+	"fmt"
+	"github.com/sanjP10/release/internal/tag"
+	"net/http"
+	urllib "net/url"
+)
+
+// DiffFile path of a file on one side of a diffstat
+type DiffFile struct {
+	Path string `json:"path"`
+}
+
+// DiffStat is a file changed between two commits on Bitbucket Cloud
+type DiffStat struct {
+	Old *DiffFile `json:"old"`
+	New *DiffFile `json:"new"`
+}
+
+// DiffStatPage paged response of the Bitbucket Cloud diffstat API
+type DiffStatPage struct {
+	Values []DiffStat `json:"values"`
+	Next   string     `json:"next"`
+}
+
+// ServerPath path of a file on Bitbucket Server
+type ServerPath struct {
+	ToString string `json:"toString"`
+}
+
+// ServerChange is a file changed between two commits on Bitbucket Server
+type ServerChange struct {
+	Path    ServerPath  `json:"path"`
+	SrcPath *ServerPath `json:"srcPath"`
+}
+
+// ServerChangePage paged response of the Bitbucket Server compare API
+type ServerChangePage struct {
+	Values        []ServerChange `json:"values"`
+	IsLastPage    bool           `json:"isLastPage"`
+	NextPageStart int            `json:"nextPageStart"`
+}
+
+// ChangedFiles lists the files changed between the base tag and the hash, with the diffstat API on Bitbucket Cloud
+// and the compare API on Bitbucket Server
+func (r *Properties) ChangedFiles(base string) ([]string, error) {
+	var files []string
+	var err error
+	if r.Host == "" {
+		files, err = r.cloudChangedFiles(base)
+	} else {
+		files, err = r.serverChangedFiles(base)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to compare %s with %s, %w", base, r.Hash, err)
+	}
+	return files, nil
+}
+
+func (r *Properties) cloudChangedFiles(base string) ([]string, error) {
+	var files []string
+	url := fmt.Sprintf("https://api.bitbucket.org/2.0/repositories/%s/diffstat/%s..%s?topic=false", r.Repo, r.Hash, urllib.PathEscape(base))
+	for url != "" {
+		page := DiffStatPage{}
+		err := r.getPage(url, &page)
+		if err != nil {
+			return nil, err
+		}
+		for _, diffStat := range page.Values {
+			if diffStat.New != nil {
+				files = append(files, diffStat.New.Path)
+			}
+			if diffStat.Old != nil && (diffStat.New == nil || diffStat.Old.Path != diffStat.New.Path) {
+				files = append(files, diffStat.Old.Path)
+			}
+		}
+		url = page.Next
+	}
+	return files, nil
+}
+
+func (r *Properties) serverChangedFiles(base string) ([]string, error) {
+	var files []string
+	repoPath, err := ServerRepoPath(r.Repo)
+	if err != nil {
+		return nil, err
+	}
+	start := 0
+	for {
+		url := fmt.Sprintf("%s/rest/api/1.0/%s/compare/changes?from=%s&to=%s&start=%d&limit=%d",
+			r.Host, repoPath, urllib.QueryEscape(r.Hash), urllib.QueryEscape(base), start, serverPageLimit)
+		page := ServerChangePage{}
+		err = r.getPage(url, &page)
+		if err != nil {
+			return nil, err
+		}
+		for _, change := range page.Values {
+			files = append(files, change.Path.ToString)
+			if change.SrcPath != nil && change.SrcPath.ToString != change.Path.ToString {
+				files = append(files, change.SrcPath.ToString)
+			}
+		}
+		if page.IsLastPage || page.NextPageStart <= start {
+			break
+		}
+		start = page.NextPageStart
+	}
+	return files, nil
+}
+
+// getPage requests a page of a paged API into page
+func (r *Properties) getPage(url string, page interface{}) error {
+	request, err := http.NewRequestWithContext(r.RequestContext(), "GET", url, nil)
+	if err != nil {
+		return err
+	}
+	r.authorize(request)
+	resp, err := r.HTTPClient().Do(request)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return tag.DecodeJSON(resp, page)
+}
@@ -0,0 +1,66 @@
+package bitbucket
+
+import (
+	"github.com/sanjP10/release/internal/tag"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+	"net/http"
+	"testing"
+)
+
+func TestChangedFilesCloud(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	gock.New("https://api.bitbucket.org").
+		Get("/2.0/repositories/owner/repo/diffstat/hash..billing/1.0.0").
+		Reply(http.StatusOK).
+		JSON(DiffStatPage{
+			Values: []DiffStat{{Old: &DiffFile{Path: "billing/main.go"}, New: &DiffFile{Path: "billing/main.go"}}},
+			Next:   "https://api.bitbucket.org/2.0/repositories/owner/repo/diffstat/hash..billing/1.0.0?page=2",
+		})
+	gock.New("https://api.bitbucket.org").
+		Get("/2.0/repositories/owner/repo/diffstat/hash..billing/1.0.0").
+		MatchParam("page", "2").
+		Reply(http.StatusOK).
+		JSON(DiffStatPage{Values: []DiffStat{{Old: &DiffFile{Path: "auth/removed.go"}}}})
+	assertTest := assert.New(t)
+	repo := Properties{Username: "user", Repo: "owner/repo", RepoProperties: tag.RepoProperties{Password: "token", Hash: "hash"}}
+	files, err := repo.ChangedFiles("billing/1.0.0")
+	assertTest.NoError(err)
+	assertTest.Equal([]string{"billing/main.go", "auth/removed.go"}, files)
+	assertTest.True(gock.IsDone())
+}
+
+func TestChangedFilesServer(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	gock.New("https://bitbucket.example.com").
+		Get("/rest/api/1.0/projects/PROJ/repos/repo/compare/changes").
+		MatchParam("from", "hash").
+		MatchParam("to", "billing/1.0.0").
+		MatchParam("start", "0").
+		Reply(http.StatusOK).
+		JSON(ServerChangePage{Values: []ServerChange{{Path: ServerPath{ToString: "billing/main.go"}}}, NextPageStart: 1})
+	gock.New("https://bitbucket.example.com").
+		Get("/rest/api/1.0/projects/PROJ/repos/repo/compare/changes").
+		MatchParam("start", "1").
+		Reply(http.StatusOK).
+		JSON(ServerChangePage{Values: []ServerChange{{Path: ServerPath{ToString: "auth/new.go"}, SrcPath: &ServerPath{ToString: "auth/old.go"}}}, IsLastPage: true})
+	assertTest := assert.New(t)
+	repo := Properties{Username: "user", Repo: "PROJ/repo", Host: "https://bitbucket.example.com", RepoProperties: tag.RepoProperties{Password: "token", Hash: "hash"}}
+	files, err := repo.ChangedFiles("billing/1.0.0")
+	assertTest.NoError(err)
+	assertTest.Equal([]string{"billing/main.go", "auth/new.go", "auth/old.go"}, files)
+}
+
+func TestChangedFilesServerNotFound(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	gock.New("https://bitbucket.example.com").
+		Get("/rest/api/1.0/projects/PROJ/repos/repo/compare/changes").
+		Reply(http.StatusNotFound).
+		BodyString("not found")
+	repo := Properties{Username: "user", Repo: "PROJ/repo", Host: "https://bitbucket.example.com", RepoProperties: tag.RepoProperties{Password: "token", Hash: "hash"}}
+	_, err := repo.ChangedFiles("1.0.0")
+	assert.EqualError(t, err, "unable to compare 1.0.0 with hash, status 404: not found")
+}
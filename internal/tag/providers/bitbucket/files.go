@@ -0,0 +1,32 @@
+package bitbucket
+
+import (
+	"fmt"
+	"github.com/sanjP10/release/internal/tag"
+	"net/http"
+	urllib "net/url"
+)
+
+// ReadFile reads the raw contents of a file at the ref, with the src API on Bitbucket Cloud and the raw API on
+// Bitbucket Server
+func (r *Properties) ReadFile(ref string, path string) (string, error) {
+	url := fmt.Sprintf("https://api.bitbucket.org/2.0/repositories/%s/src/%s/%s", r.Repo, urllib.PathEscape(ref), tag.EscapePath(path))
+	if r.Host != "" {
+		repoPath, err := ServerRepoPath(r.Repo)
+		if err != nil {
+			return "", err
+		}
+		url = fmt.Sprintf("%s/rest/api/1.0/%s/raw/%s?at=%s", r.Host, repoPath, tag.EscapePath(path), urllib.QueryEscape(ref))
+	}
+	request, err := http.NewRequestWithContext(r.RequestContext(), "GET", url, nil)
+	if err != nil {
+		return "", err
+	}
+	r.authorize(request)
+	resp, err := r.HTTPClient().Do(request)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	return tag.ReadFileResponse(resp)
+}
@@ -0,0 +1,35 @@
+package bitbucket
+
+import (
+	"github.com/sanjP10/release/internal/tag"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+	"net/http"
+	"testing"
+)
+
+func TestReadFileCloud(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	gock.New("https://api.bitbucket.org").
+		Get("/2.0/repositories/owner/repo/src/main/CHANGELOG.md").
+		Reply(http.StatusOK).
+		BodyString("## 1.0.0")
+	assertTest := assert.New(t)
+	repo := Properties{Username: "user", Repo: "owner/repo", RepoProperties: tag.RepoProperties{Password: "token"}}
+	contents, err := repo.ReadFile("main", "CHANGELOG.md")
+	assertTest.NoError(err)
+	assertTest.Equal("## 1.0.0", contents)
+}
+
+func TestReadFileServer(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	gock.New("https://bitbucket.example.com").
+		Get("/rest/api/1.0/users/jdoe/repos/repo/raw/CHANGELOG.md").
+		MatchParam("at", "main").
+		Reply(http.StatusNotFound)
+	repo := Properties{Username: "user", Repo: "~jdoe/repo", Host: "https://bitbucket.example.com", RepoProperties: tag.RepoProperties{Password: "token"}}
+	_, err := repo.ReadFile("main", "CHANGELOG.md")
+	assert.ErrorIs(t, err, tag.ErrFileNotFound)
+}
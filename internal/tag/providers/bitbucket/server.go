@@ -0,0 +1,127 @@
+package bitbucket
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/sanjP10/release/internal/tag"
+	"io/ioutil"
+	"net/http"
+	urllib "net/url"
+	"os"
+	"strings"
+)
+
+// serverPageLimit is the number of tags requested per page from Bitbucket Server
+const serverPageLimit = 100
+
+// ServerTagPage paged response of Bitbucket Server tags
+type ServerTagPage struct {
+	Values        []ServerTag `json:"values"`
+	IsLastPage    bool        `json:"isLastPage"`
+	NextPageStart int         `json:"nextPageStart"`
+}
+
+// ServerRepoPath is the path of the repo in the Bitbucket Server API, projects/KEY/repos/slug or users/user/repos/slug for ~user/slug
+func ServerRepoPath(repo string) (string, error) {
+	repoDetails := strings.Split(repo, "/")
+	if len(repoDetails) != 2 || repoDetails[0] == "" || repoDetails[1] == "" || repoDetails[0] == "~" {
+		return "", fmt.Errorf("repo %q must be in the format project/repo, or ~user/repo for a personal repo", repo)
+	}
+	owner, slug := urllib.PathEscape(repoDetails[0]), urllib.PathEscape(repoDetails[1])
+	if strings.HasPrefix(repoDetails[0], "~") {
+		return fmt.Sprintf("users/%s/repos/%s", urllib.PathEscape(strings.TrimPrefix(repoDetails[0], "~")), slug), nil
+	}
+	return fmt.Sprintf("projects/%s/repos/%s", owner, slug), nil
+}
+
+// ReleaseURL is the source of the repository at the tag, Bitbucket has no release pages
+func (r *Properties) ReleaseURL() string {
+	if r.Host == "" {
+		return fmt.Sprintf("https://bitbucket.org/%s/src/%s", r.Repo, urllib.PathEscape(r.Tag))
+	}
+	repoPath, err := ServerRepoPath(r.Repo)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%s/%s/browse?at=%s", r.Host, repoPath, urllib.QueryEscape("refs/tags/"+r.Tag))
+}
+
+// validateServerTag pages through the tags matching the tag name, tags are only found by exact name
+func (r *Properties) validateServerTag() tag.ValidTagState {
+	validTag := tag.ValidTagState{TagDoesntExist: false, TagExistsWithProvidedHash: false}
+	repoPath, err := ServerRepoPath(r.Repo)
+	if err != nil {
+		fmt.Println("Error validate tag request", err)
+		return tag.UnknownTagState(err)
+	}
+	start := 0
+	for {
+		url := fmt.Sprintf("%s/rest/api/1.0/%s/tags?filterText=%s&start=%d&limit=%d",
+			r.Host, repoPath, urllib.QueryEscape(r.Tag), start, serverPageLimit)
+		page, state := r.serverTagPage(url)
+		if state.Unknown {
+			return state
+		}
+		for _, serverTag := range page.Values {
+			if serverTag.DisplayID == r.Tag {
+				validTag.TagExistsWithProvidedHash = serverTag.LatestCommit == r.Hash
+				return validTag
+			}
+		}
+		if page.IsLastPage || page.NextPageStart <= start {
+			break
+		}
+		start = page.NextPageStart
+	}
+	validTag.TagDoesntExist = true
+	return validTag
+}
+
+// serverTagPage requests a page of tags, the state is unknown when the page could not be read
+func (r *Properties) serverTagPage(url string) (ServerTagPage, tag.ValidTagState) {
+	page := ServerTagPage{}
+	request, err := http.NewRequestWithContext(r.RequestContext(), "GET", url, nil)
+	if err != nil {
+		fmt.Println("Error validate tag request")
+		return page, tag.UnknownTagState(err)
+	}
+	r.authorize(request)
+	client := r.HTTPClient()
+	resp, err := client.Do(request)
+	if err != nil {
+		fmt.Println("Error validate tag request")
+	}
+	if resp == nil {
+		_, errorWriting := os.Stderr.WriteString("Error getting response\n")
+		if errorWriting != nil {
+			panic("Cannot write to stderr")
+		}
+		return page, tag.UnknownTagState(err)
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			fmt.Println("Error reading body of tag response")
+			return page, tag.UnknownTagState(fmt.Errorf("unable to read tag response: %w", err))
+		}
+		err = json.Unmarshal(body, &page)
+		if err != nil {
+			fmt.Println("Error unmarshalling body")
+			return page, tag.UnknownTagState(fmt.Errorf("unexpected tag response: %w", err))
+		}
+		return page, tag.ValidTagState{}
+	case http.StatusUnauthorized:
+		_, err := os.Stderr.WriteString("Unauthorised, please check credentials\n")
+		if err != nil {
+			panic("Cannot write to stderr")
+		}
+	case http.StatusNotFound:
+		_, err := os.Stderr.WriteString("Repo " + r.Repo + " not found\n")
+		if err != nil {
+			panic("Cannot write to stderr")
+		}
+	}
+	return page, tag.UnexpectedResponse(resp)
+}
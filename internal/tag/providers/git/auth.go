@@ -0,0 +1,167 @@
+package git
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	gossh "golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// HTTP authentication schemes supported for https origins
+const (
+	HTTPAuthBasic  = "basic"
+	HTTPAuthBearer = "bearer"
+)
+
+// ValidHTTPAuth checks the http authentication scheme from the cli is supported
+func ValidHTTPAuth(scheme string) bool {
+	switch strings.ToLower(scheme) {
+	case "", HTTPAuthBasic, HTTPAuthBearer:
+		return true
+	}
+	return false
+}
+
+// getAuth builds the authentication for the origin, ssh uses the key file or ssh-agent and https uses basic or bearer auth
+func (r *Properties) getAuth() (transport.AuthMethod, error) {
+	endpoint, err := transport.NewEndpoint(r.originURL())
+	if err != nil {
+		return nil, err
+	}
+	if len(r.SSH) == 0 && endpoint.Protocol != "ssh" {
+		return r.getHTTPAuth(endpoint), nil
+	}
+
+	username := r.Username
+	if username == "" {
+		username = endpoint.User
+	}
+	if username == "" {
+		username = "git"
+	}
+	hostKeyCallback, hostKeyAlgorithms, err := r.hostKeyCallback(endpoint)
+	if err != nil {
+		fmt.Println("Error Setting SSH known hosts", err)
+		return nil, err
+	}
+	if len(r.SSH) > 0 {
+		pemBytes, err := os.ReadFile(r.SSH)
+		if err != nil {
+			fmt.Println("Error Reading SSH Key", err)
+			return nil, err
+		}
+		auth, err := ssh.NewPublicKeys(username, pemBytes, r.Password)
+		if err != nil && r.Password == "" {
+			// the passphrase retry hides that the key is encrypted
+			if _, parseErr := gossh.ParsePrivateKey(pemBytes); parseErr != nil {
+				err = parseErr
+			}
+		}
+		if err != nil {
+			err = describeKeyError(r.SSH, err)
+			fmt.Println("Error Setting SSH Key", err)
+			return nil, err
+		}
+		auth.HostKeyCallback = hostKeyCallback
+		auth.HostKeyAlgorithms = hostKeyAlgorithms
+		return auth, nil
+	}
+	auth, err := ssh.NewSSHAgentAuth(username)
+	if err != nil {
+		err = fmt.Errorf("no -ssh key provided and ssh-agent is unavailable: %w", err)
+		fmt.Println("Error Setting SSH Agent", err)
+		return nil, err
+	}
+	auth.HostKeyCallback = hostKeyCallback
+	auth.HostKeyAlgorithms = hostKeyAlgorithms
+	return auth, nil
+}
+
+// getHTTPAuth uses the password or token provided, otherwise the git credential helpers or netrc for the host
+func (r *Properties) getHTTPAuth(endpoint *transport.Endpoint) transport.AuthMethod {
+	username, password := r.Username, r.Password
+	if password == "" && endpoint.Password == "" {
+		var found bool
+		username, password, found = credentialFill(r.RequestContext(), endpoint, username)
+		if !found {
+			username, password, found = netrcLookup(endpoint.Host, r.Username)
+		}
+		if !found {
+			// credentials in the origin url are used by the transport
+			return nil
+		}
+	}
+	if strings.ToLower(r.HTTPAuth) == HTTPAuthBearer {
+		return &http.TokenAuth{Token: password}
+	}
+	if username == "" && password == "" {
+		return nil
+	}
+	return &http.BasicAuth{Username: username, Password: password}
+}
+
+// originURL is the url of the remote in use, or the origin flag before the remote is set up
+func (r *Properties) originURL() string {
+	if r.remote != nil && len(r.remote.Config().URLs) > 0 {
+		return r.remote.Config().URLs[0]
+	}
+	return r.Origin
+}
+
+// hostKeyCallback verifies ssh host keys against the known hosts file, the defaults or not at all when insecure
+func (r *Properties) hostKeyCallback(endpoint *transport.Endpoint) (gossh.HostKeyCallback, []string, error) {
+	if r.InsecureIgnoreHostKey {
+		return gossh.InsecureIgnoreHostKey(), nil, nil
+	}
+	var files []string
+	if len(r.KnownHosts) > 0 {
+		files = append(files, r.KnownHosts)
+	}
+	db, err := ssh.NewKnownHostsDb(files...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w, provide -known-hosts or -insecure-ignore-host-key", err)
+	}
+	port := endpoint.Port
+	if port == 0 {
+		port = 22
+	}
+	algorithms := db.HostKeyAlgorithms(net.JoinHostPort(endpoint.Host, strconv.Itoa(port)))
+	return describeHostKeyError(db.HostKeyCallback()), algorithms, nil
+}
+
+// describeHostKeyError distinguishes an unknown host from a host key mismatch in verification errors
+func describeHostKeyError(callback gossh.HostKeyCallback) gossh.HostKeyCallback {
+	return func(hostname string, address net.Addr, key gossh.PublicKey) error {
+		err := callback(hostname, address, key)
+		var keyErr *knownhosts.KeyError
+		if errors.As(err, &keyErr) {
+			if len(keyErr.Want) > 0 {
+				return fmt.Errorf("host key mismatch for %s, the server presented %s which does not match known hosts: %w",
+					hostname, gossh.FingerprintSHA256(key), err)
+			}
+			return fmt.Errorf("host %s is not in known hosts, add %s to known hosts or provide -known-hosts: %w",
+				hostname, gossh.FingerprintSHA256(key), err)
+		}
+		return err
+	}
+}
+
+// describeKeyError distinguishes an encrypted key without a passphrase or a wrong passphrase from other key errors
+func describeKeyError(filePath string, err error) error {
+	var passphraseMissing *gossh.PassphraseMissingError
+	if errors.As(err, &passphraseMissing) {
+		return fmt.Errorf("ssh key %s is encrypted, provide its passphrase with -password", filePath)
+	}
+	if errors.Is(err, x509.IncorrectPasswordError) {
+		return fmt.Errorf("unable to decrypt ssh key %s, check the -password provided: %w", filePath, err)
+	}
+	return fmt.Errorf("unable to read ssh key %s: %w", filePath, err)
+}
@@ -0,0 +1,63 @@
+package git
+
+import (
+	"fmt"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// ChangedFiles lists the files changed between the base tag and the hash by comparing the trees of both commits,
+// only the two commits are fetched as history is not needed to compare them
+func (r *Properties) ChangedFiles(base string) ([]string, error) {
+	tagName := plumbing.NewTagReferenceName(base)
+	tagRef := r.remoteRef(tagName)
+	if tagRef == nil {
+		return nil, fmt.Errorf("tag %s not found on the origin", base)
+	}
+	baseHash, err := r.peelTag(tagRef)
+	if err != nil {
+		return nil, err
+	}
+	baseCommit, err := r.repository.CommitObject(baseHash)
+	if err != nil {
+		err = r.fetch(r.shallowDepth(), config.RefSpec("+"+tagName.String()+":"+tagName.String()))
+		if err != nil {
+			return nil, err
+		}
+		baseCommit, err = r.repository.CommitObject(baseHash)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = r.fetchCommit()
+	if err != nil {
+		return nil, err
+	}
+	headCommit, err := r.repository.CommitObject(plumbing.NewHash(r.Hash))
+	if err != nil {
+		return nil, err
+	}
+	baseTree, err := baseCommit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	headTree, err := headCommit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	changes, err := object.DiffTreeWithOptions(r.RequestContext(), baseTree, headTree, nil)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, change := range changes {
+		if change.From.Name != "" {
+			files = append(files, change.From.Name)
+		}
+		if change.To.Name != "" && change.To.Name != change.From.Name {
+			files = append(files, change.To.Name)
+		}
+	}
+	return files, nil
+}
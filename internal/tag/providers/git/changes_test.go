@@ -0,0 +1,44 @@
+package git
+
+import (
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/sanjP10/release/internal/tag"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestChangedFiles(t *testing.T) {
+	assertTest := assert.New(t)
+	originPath, clonePath, hash := initTestRemote(t)
+	repo := Properties{Origin: originPath, Email: "tester@example.com", Username: "tester",
+		RepoProperties: tag.RepoProperties{Tag: "billing/1.0.0", Hash: hash.String(), Body: "notes"}}
+	assertTest.NoError(repo.InitializeRepository())
+	assertTest.True(repo.CreateTag())
+
+	clone, err := git.PlainOpen(clonePath)
+	assertTest.NoError(err)
+	worktree, _ := clone.Worktree()
+	assertTest.NoError(os.MkdirAll(filepath.Join(clonePath, "billing"), 0700))
+	assertTest.NoError(os.WriteFile(filepath.Join(clonePath, "billing", "main.go"), []byte("package main"), 0600))
+	_, err = worktree.Add("billing/main.go")
+	assertTest.NoError(err)
+	head, err := worktree.Commit("add billing", &git.CommitOptions{
+		Author: &object.Signature{Name: "tester", Email: "tester@example.com", When: time.Now()},
+	})
+	assertTest.NoError(err)
+	assertTest.NoError(clone.Push(&git.PushOptions{RemoteName: "origin", RefSpecs: []config.RefSpec{"refs/heads/*:refs/heads/*"}}))
+
+	repo = Properties{Origin: originPath, RepoProperties: tag.RepoProperties{Hash: head.String()}}
+	assertTest.NoError(repo.InitializeRepository())
+	files, err := repo.ChangedFiles("billing/1.0.0")
+	assertTest.NoError(err)
+	assertTest.Equal([]string{"billing/main.go"}, files)
+
+	_, err = repo.ChangedFiles("billing/0.9.0")
+	assertTest.EqualError(err, "tag billing/0.9.0 not found on the origin")
+}
@@ -0,0 +1,131 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// gitExecutable is the git binary used to query credential helpers
+var gitExecutable = "git"
+
+// credentialFill asks the git credential helpers configured for the user for the credentials of the endpoint
+func credentialFill(ctx context.Context, endpoint *transport.Endpoint, username string) (string, string, bool) {
+	request := &strings.Builder{}
+	request.WriteString("protocol=" + endpoint.Protocol + "\n")
+	request.WriteString("host=" + endpoint.Host + "\n")
+	if endpoint.Path != "" {
+		request.WriteString("path=" + strings.TrimPrefix(endpoint.Path, "/") + "\n")
+	}
+	if username != "" {
+		request.WriteString("username=" + username + "\n")
+	}
+	request.WriteString("\n")
+
+	command := exec.CommandContext(ctx, gitExecutable, "credential", "fill")
+	command.Stdin = strings.NewReader(request.String())
+	// never fall back to prompting on the terminal
+	command.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GIT_ASKPASS=", "SSH_ASKPASS=")
+	output, err := command.Output()
+	if err != nil {
+		return "", "", false
+	}
+	return parseCredentialOutput(output)
+}
+
+// parseCredentialOutput reads the username and password from the key=value output of git credential fill
+func parseCredentialOutput(output []byte) (string, string, bool) {
+	var username, password string
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		key, value, found := strings.Cut(scanner.Text(), "=")
+		if !found {
+			continue
+		}
+		switch key {
+		case "username":
+			username = value
+		case "password":
+			password = value
+		}
+	}
+	return username, password, password != ""
+}
+
+// netrcLookup finds the login and password for the host in the netrc file, NETRC overrides the default location
+func netrcLookup(host string, username string) (string, string, bool) {
+	path := os.Getenv("NETRC")
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", "", false
+		}
+		path = filepath.Join(home, ".netrc")
+		if runtime.GOOS == "windows" {
+			path = filepath.Join(home, "_netrc")
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", "", false
+	}
+	return parseNetrc(string(data), host, username)
+}
+
+// parseNetrc returns the first machine entry matching the host (and username when provided), or the default entry
+func parseNetrc(data string, host string, username string) (string, string, bool) {
+	type entry struct {
+		machine  string
+		login    string
+		password string
+	}
+	var entries []entry
+	var current *entry
+	tokens := strings.Fields(data)
+	for i := 0; i < len(tokens); i++ {
+		switch tokens[i] {
+		case "machine":
+			if i+1 < len(tokens) {
+				i++
+				entries = append(entries, entry{machine: tokens[i]})
+				current = &entries[len(entries)-1]
+			}
+		case "default":
+			entries = append(entries, entry{})
+			current = &entries[len(entries)-1]
+		case "login":
+			if current != nil && i+1 < len(tokens) {
+				i++
+				current.login = tokens[i]
+			}
+		case "password":
+			if current != nil && i+1 < len(tokens) {
+				i++
+				current.password = tokens[i]
+			}
+		case "macdef":
+			// macros run until a blank line which Fields has discarded, stop as nothing after is reliable
+			i = len(tokens)
+		}
+	}
+	hostname := strings.Split(host, ":")[0]
+	for _, candidate := range entries {
+		if candidate.machine != "" && candidate.machine != hostname {
+			continue
+		}
+		if username != "" && candidate.login != "" && candidate.login != username {
+			continue
+		}
+		if candidate.password == "" {
+			continue
+		}
+		return candidate.login, candidate.password, true
+	}
+	return "", "", false
+}
@@ -0,0 +1,67 @@
+package git
+
+import (
+	"errors"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/sanjP10/release/internal/tag"
+)
+
+// ReadFile reads a file at a branch, tag or commit of the repository opened with OpenRepository, branches and commits
+// missing from the repository are fetched from the origin
+func (r *Properties) ReadFile(ref string, path string) (string, error) {
+	commit, err := r.resolveCommit(ref)
+	if err != nil {
+		return "", err
+	}
+	file, err := commit.File(path)
+	if errors.Is(err, object.ErrFileNotFound) {
+		return "", tag.ErrFileNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return file.Contents()
+}
+
+// resolveCommit finds the commit of a local revision, or a branch or tag of the origin, fetching the branch, tag or
+// commit when it is not available locally
+func (r *Properties) resolveCommit(ref string) (*object.Commit, error) {
+	if commit, ok := r.localCommit(ref); ok {
+		return commit, nil
+	}
+	err := r.fetch(r.shallowDepth(), config.RefSpec("+refs/heads/"+ref+":refs/remotes/origin/"+ref))
+	if err != nil && !plumbing.IsHash(ref) {
+		err = r.fetch(r.shallowDepth(), config.RefSpec("+refs/tags/"+ref+":refs/tags/"+ref))
+	}
+	if err != nil && plumbing.IsHash(ref) {
+		// not a branch, fetching a single commit by hash requires server support, otherwise fall back to fetching the branches
+		err = r.fetch(r.shallowDepth(), config.RefSpec(ref+":refs/release/"+ref))
+		if err != nil {
+			err = r.fetch(0, "+refs/heads/*:refs/remotes/origin/*")
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if commit, ok := r.localCommit(ref); ok {
+		return commit, nil
+	}
+	return nil, errors.New("unable to find " + ref)
+}
+
+// localCommit resolves a branch of the origin, or a local revision such as a tag, to a commit available in the repository
+func (r *Properties) localCommit(ref string) (*object.Commit, bool) {
+	for _, revision := range []string{"origin/" + ref, ref} {
+		hash, err := r.repository.ResolveRevision(plumbing.Revision(revision))
+		if err != nil {
+			continue
+		}
+		commit, err := r.repository.CommitObject(*hash)
+		if err == nil {
+			return commit, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,43 @@
+package git
+
+import (
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/sanjP10/release/internal/tag"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReadFileTagBase(t *testing.T) {
+	assertTest := assert.New(t)
+	originPath, clonePath, _ := initTestRemote(t)
+	clone, err := git.PlainOpen(clonePath)
+	assertTest.NoError(err)
+	worktree, _ := clone.Worktree()
+	assertTest.NoError(os.WriteFile(filepath.Join(clonePath, "CHANGELOG.md"), []byte("## 1.0.0\n"), 0600))
+	_, err = worktree.Add("CHANGELOG.md")
+	assertTest.NoError(err)
+	hash, err := worktree.Commit("add changelog", &git.CommitOptions{
+		Author: &object.Signature{Name: "tester", Email: "tester@example.com", When: time.Now()},
+	})
+	assertTest.NoError(err)
+	assertTest.NoError(clone.Push(&git.PushOptions{RemoteName: "origin", RefSpecs: []config.RefSpec{"refs/heads/*:refs/heads/*"}}))
+	repo := Properties{Origin: originPath, Email: "tester@example.com", Username: "tester",
+		RepoProperties: tag.RepoProperties{Tag: "v1.0.0", Hash: hash.String(), Body: "notes"}}
+	assertTest.NoError(repo.InitializeRepository())
+	assertTest.True(repo.CreateTag())
+
+	// a fresh in memory repository fetches the tag from the origin
+	repo = Properties{Origin: originPath}
+	assertTest.NoError(repo.InitializeRepository())
+	content, err := repo.ReadFile("v1.0.0", "CHANGELOG.md")
+	assertTest.NoError(err)
+	assertTest.Equal("## 1.0.0\n", content)
+
+	_, err = repo.ReadFile("v2.0.0", "CHANGELOG.md")
+	assertTest.Error(err)
+}
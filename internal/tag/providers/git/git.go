@@ -1,17 +1,17 @@
 package git
 
 import (
+	"errors"
 	"fmt"
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
-	"github.com/go-git/go-git/v5/plumbing/transport"
-	"github.com/go-git/go-git/v5/plumbing/transport/http"
-	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
 	"github.com/go-git/go-git/v5/storage/memory"
 	"github.com/sanjP10/release/internal/tag"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -22,38 +22,132 @@ type Properties struct {
 	Email    string
 	Origin   string
 	SSH      string
+	// HTTPAuth is the authentication scheme for https origins, basic (default) or bearer with the password as the token
+	HTTPAuth string
+	// KnownHosts is a known_hosts file used to verify ssh host keys instead of the defaults
+	KnownHosts string
+	// InsecureIgnoreHostKey disables ssh host key verification
+	InsecureIgnoreHostKey bool
+	// RepoPath is an existing local clone to use instead of fetching the origin into memory
+	RepoPath string
+	// TaggerName is the name recorded on the tag, defaults to Username
+	TaggerName string
+	// TaggerEmail is the email recorded on the tag, defaults to Email
+	TaggerEmail string
+	// Lightweight creates a tag reference pointing straight at the commit instead of an annotated tag object
+	Lightweight bool
+	// SignKey is the private key file used to sign the tag, the tag is unsigned when empty
+	SignKey string
+	// SignFormat is the format of SignKey, either openpgp (default) or ssh
+	SignFormat string
+	// SignPassword is the passphrase for SignKey if it is encrypted
+	SignPassword string
+
+	repository *git.Repository
+	remote     *git.Remote
+	remoteRefs []*plumbing.Reference
 }
 
-var repository *git.Repository
+// Kinds of tag that can be created
+const (
+	TagTypeAnnotated   = "annotated"
+	TagTypeLightweight = "lightweight"
+)
 
-// InitializeRepository initializes the repository sets up origins and fetches
+// ValidTagType checks the kind of tag from the cli is supported
+func ValidTagType(tagType string) bool {
+	switch strings.ToLower(tagType) {
+	case "", TagTypeAnnotated, TagTypeLightweight:
+		return true
+	}
+	return false
+}
+
+// InitializeRepository opens the local clone at RepoPath or an empty in memory repository, sets up the origin and lists its refs
 func (r *Properties) InitializeRepository() error {
+	err := r.OpenRepository()
+	if err != nil {
+		return err
+	}
+	auth, err := r.getAuth()
+	if err != nil {
+		return err
+	}
+	// Only the ref advertisement is needed to know whether the tag exists, objects are fetched on demand
+	r.remoteRefs, err = r.remote.ListContext(r.RequestContext(), &git.ListOptions{Auth: auth})
+	if err != nil {
+		fmt.Println("Error Listing remote references", err)
+	}
+	return err
+}
+
+// OpenRepository opens the local clone at RepoPath or an empty in memory repository and sets up the origin without
+// contacting it
+func (r *Properties) OpenRepository() error {
 	var err error
-	repository, err = git.Init(memory.NewStorage(), nil)
+	if r.RepoPath != "" {
+		r.repository, err = git.PlainOpenWithOptions(r.RepoPath, &git.PlainOpenOptions{DetectDotGit: true})
+	} else {
+		r.repository, err = git.Init(memory.NewStorage(), nil)
+	}
 	if err != nil {
 		fmt.Println("Error Initializing repository", err)
 		return err
 	}
-	_, err = repository.CreateRemote(&config.RemoteConfig{
-		Name: "origin",
-		URLs: []string{r.Origin},
-	})
+	err = r.setRemote()
 	if err != nil {
 		fmt.Println("Error Setting origin for repository", err)
-		return err
 	}
-	auth, err := getAuth(r.SSH, r.Username, r.Password)
-	if err != nil {
+	return err
+}
+
+// setRemote uses the origin flag when provided, otherwise the origin remote of the local clone
+func (r *Properties) setRemote() error {
+	if r.Origin == "" {
+		if r.RepoPath == "" {
+			return errors.New("an origin is required when a repository path is not provided")
+		}
+		var err error
+		r.remote, err = r.repository.Remote("origin")
 		return err
 	}
-	err = repository.Fetch(&git.FetchOptions{
-		RemoteName: "origin",
-		RefSpecs:   []config.RefSpec{"+refs/tags/*:refs/tags/*", "+refs/heads/*:refs/remotes/origin/*"},
-		Auth:       auth,
+	// An anonymous remote avoids rewriting the configuration of a local clone
+	r.remote = git.NewRemote(r.repository.Storer, &config.RemoteConfig{
+		Name: "origin",
+		URLs: []string{r.Origin},
 	})
+	return r.remote.Config().Validate()
+}
 
+// remoteTag finds the tag reference advertised by the origin
+func (r *Properties) remoteTag() *plumbing.Reference {
+	return r.remoteRef(plumbing.NewTagReferenceName(r.Tag))
+}
+
+// remoteRef finds a reference advertised by the origin
+func (r *Properties) remoteRef(name plumbing.ReferenceName) *plumbing.Reference {
+	for _, ref := range r.remoteRefs {
+		if ref.Name() == name {
+			return ref
+		}
+	}
+	return nil
+}
+
+// fetch retrieves objects for the refspecs from the origin into the repository, a depth of 0 fetches the full history
+func (r *Properties) fetch(depth int, refSpecs ...config.RefSpec) error {
+	auth, err := r.getAuth()
 	if err != nil {
-		fmt.Println("Error Fetching repository", err)
+		return err
+	}
+	err = r.remote.FetchContext(r.RequestContext(), &git.FetchOptions{
+		RefSpecs: refSpecs,
+		Auth:     auth,
+		Depth:    depth,
+		Tags:     git.NoTags,
+	})
+	if err == git.NoErrAlreadyUpToDate {
+		err = nil
 	}
 	return err
 }
@@ -61,79 +155,199 @@ func (r *Properties) InitializeRepository() error {
 // ValidateTag checks a tag does not exist or has the same hash
 func (r *Properties) ValidateTag() tag.ValidTagState {
 	validTag := tag.ValidTagState{TagDoesntExist: false, TagExistsWithProvidedHash: false}
-	tagRef, err := repository.Tag(r.Tag)
+	tagRef := r.remoteTag()
+	if tagRef == nil {
+		validTag.TagDoesntExist = true
+		return validTag
+	}
+	target, err := r.peelTag(tagRef)
 	if err != nil {
-		if err.Error() == "tag not found" {
-			validTag.TagDoesntExist = true
+		fmt.Println("Error retrieving tag details", err)
+		return tag.UnknownTagState(err)
+	}
+	if target.String() == r.Hash {
+		validTag.TagExistsWithProvidedHash = true
+	}
+	return validTag
+}
+
+// peelTag resolves the commit an annotated tag points at, using the peeled reference advertised by the origin when available
+func (r *Properties) peelTag(tagRef *plumbing.Reference) (plumbing.Hash, error) {
+	peeledName := plumbing.ReferenceName(tagRef.Name().String() + "^{}")
+	for _, ref := range r.remoteRefs {
+		if ref.Name() == peeledName {
+			return ref.Hash(), nil
 		}
-	} else {
-		tagObject, err := repository.TagObject(tagRef.Hash())
+	}
+	// A lightweight tag points straight at the commit so has no peeled reference
+	if tagRef.Hash().String() == r.Hash {
+		return tagRef.Hash(), nil
+	}
+	// Not every server advertises peeled references, read the target from the tag itself
+	rawObject, err := r.loadTag(tagRef)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	if rawObject.Type() != plumbing.TagObject {
+		// lightweight tag
+		return tagRef.Hash(), nil
+	}
+	tagObject, err := object.DecodeTag(r.repository.Storer, rawObject)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	// peel tags of tags down to the commit
+	for tagObject.TargetType == plumbing.TagObject {
+		tagObject, err = r.repository.TagObject(tagObject.Target)
 		if err != nil {
-			fmt.Println("Error retrieving tag details", err)
+			return plumbing.ZeroHash, err
 		}
-		if tagObject.Target.String() == r.Hash {
-			validTag.TagExistsWithProvidedHash = true
+	}
+	return tagObject.Target, nil
+}
+
+// loadTag reads the object a tag reference points at, fetching only the tag when it is not available locally
+func (r *Properties) loadTag(tagRef *plumbing.Reference) (plumbing.EncodedObject, error) {
+	rawObject, err := r.repository.Storer.EncodedObject(plumbing.AnyObject, tagRef.Hash())
+	if err == nil {
+		return rawObject, nil
+	}
+	err = r.fetch(r.shallowDepth(), config.RefSpec("+"+tagRef.Name().String()+":"+tagRef.Name().String()))
+	if err != nil {
+		return nil, err
+	}
+	return r.repository.Storer.EncodedObject(plumbing.AnyObject, tagRef.Hash())
+}
+
+// shallowDepth limits fetches to the requested objects, a local clone is never made shallow as it has most history already
+func (r *Properties) shallowDepth() int {
+	if r.RepoPath != "" {
+		return 0
+	}
+	return 1
+}
+
+// fetchCommit retrieves the commit being tagged when it is not already available locally
+func (r *Properties) fetchCommit() error {
+	hash := plumbing.NewHash(r.Hash)
+	if _, err := r.repository.CommitObject(hash); err == nil {
+		return nil
+	}
+	// Fetching a single commit by hash requires server support, otherwise fall back to fetching the branches
+	err := r.fetch(r.shallowDepth(), config.RefSpec(r.Hash+":refs/release/target"))
+	if err == nil {
+		if _, err = r.repository.CommitObject(hash); err == nil {
+			return nil
 		}
 	}
-	return validTag
+	return r.fetch(0, "+refs/heads/*:refs/remotes/origin/*")
 }
 
 // CreateTag creates a git tag
 func (r *Properties) CreateTag() bool {
-	createTag := false
 	validTagState := r.ValidateTag()
-	if validTagState.TagExistsWithProvidedHash {
-		createTag = true
-	} else if validTagState.TagDoesntExist {
-		_, err := repository.CreateTag(r.Tag, plumbing.NewHash(r.Hash), &git.CreateTagOptions{
-			Tagger: &object.Signature{
-				Name:  r.Username,
-				Email: r.Email,
-				When:  time.Time{},
-			},
-			Message: r.Body,
-		})
-		if err != nil {
-			fmt.Println("Error Creating tag", err)
-			return createTag
-		}
-		auth, err := getAuth(r.SSH, r.Username, r.Password)
+	switch {
+	case validTagState.TagExistsWithProvidedHash:
+		return true
+	case validTagState.Unknown:
+		return r.Fail(0, "unable to check tag, "+validTagState.Message)
+	case !validTagState.TagDoesntExist:
+		return r.Fail(0, "tag already exists at another commit")
+	}
+	err := r.fetchCommit()
+	if err != nil {
+		fmt.Println("Error Fetching repository", err)
+		return r.FailRequest(fmt.Errorf("unable to fetch commit: %w", err))
+	}
+	err = r.createTagObject()
+	if err != nil {
+		fmt.Println("Error Creating tag", err)
+		return r.FailRequest(fmt.Errorf("unable to create tag object: %w", err))
+	}
+	auth, err := r.getAuth()
+	if err != nil {
+		return r.FailRequest(fmt.Errorf("unable to authenticate: %w", err))
+	}
+	po := &git.PushOptions{
+		RemoteName: "origin",
+		Progress:   os.Stdout,
+		RefSpecs:   []config.RefSpec{config.RefSpec("refs/tags/" + r.Tag + ":refs/tags/" + r.Tag)},
+		Auth:       auth,
+	}
+	err = r.remote.PushContext(r.RequestContext(), po)
+	if err != nil {
+		fmt.Println("Error Pushing tag", err)
+		return r.FailRequest(fmt.Errorf("unable to push tag: %w", err))
+	}
+	return true
+}
+
+func (r *Properties) createTagObject() error {
+	if r.Lightweight {
+		_, err := r.repository.CreateTag(r.Tag, plumbing.NewHash(r.Hash), nil)
+		return err
+	}
+	tagger, err := r.tagger()
+	if err != nil {
+		fmt.Println("Error Setting tagger", err)
+		return err
+	}
+	if r.SignKey != "" && strings.ToLower(r.SignFormat) == SignFormatSSH {
+		signer, err := readSSHSigner(r.SignKey, r.SignPassword)
 		if err != nil {
-			return createTag
+			fmt.Println("Error Reading SSH signing key", err)
+			return err
 		}
-		po := &git.PushOptions{
-			RemoteName: "origin",
-			Progress:   os.Stdout,
-			RefSpecs:   []config.RefSpec{config.RefSpec("refs/tags/" + r.Tag + ":refs/tags/" + r.Tag)},
-			Auth:       auth,
-		}
-		err = repository.Push(po)
+		_, err = r.createSSHSignedTag(r.Tag, plumbing.NewHash(r.Hash), tagger, r.Body, signer)
+		return err
+	}
+	options := &git.CreateTagOptions{
+		Tagger:  &tagger,
+		Message: r.Body,
+	}
+	if r.SignKey != "" {
+		signKey, err := readOpenPGPKey(r.SignKey, r.SignPassword)
 		if err != nil {
-			fmt.Println("Error Pushing tag", err)
-			return createTag
+			fmt.Println("Error Reading OpenPGP signing key", err)
+			return err
 		}
-		createTag = true
+		options.SignKey = signKey
 	}
-	return createTag
+	_, err = r.repository.CreateTag(r.Tag, plumbing.NewHash(r.Hash), options)
+	return err
 }
 
-func getAuth(filePath string, username string, password string) (transport.AuthMethod, error) {
-	var auth transport.AuthMethod
-	var err error
-	if len(filePath) > 0 {
-		if username == "" {
-			username = "git"
+// tagger builds the tag signature, dated SOURCE_DATE_EPOCH when set for reproducible tags otherwise the current time
+func (r *Properties) tagger() (object.Signature, error) {
+	tagger := object.Signature{
+		Name:  r.TaggerName,
+		Email: r.TaggerEmail,
+		When:  time.Now(),
+	}
+	if tagger.Name == "" {
+		tagger.Name = r.Username
+	}
+	if tagger.Email == "" {
+		tagger.Email = r.Email
+	}
+	if tagger.Name == "" || tagger.Email == "" {
+		// fall back to the identity configured for a local clone
+		cfg, err := r.repository.ConfigScoped(config.GlobalScope)
+		if err == nil {
+			if tagger.Name == "" {
+				tagger.Name = cfg.User.Name
+			}
+			if tagger.Email == "" {
+				tagger.Email = cfg.User.Email
+			}
 		}
-		auth, err = ssh.NewPublicKeysFromFile(username, filePath, password)
+	}
+	if epoch, ok := os.LookupEnv("SOURCE_DATE_EPOCH"); ok && epoch != "" {
+		seconds, err := strconv.ParseInt(epoch, 10, 64)
 		if err != nil {
-			fmt.Println("Error Setting SSH Key", err)
-			return nil, err
-		}
-	} else {
-		auth = &http.BasicAuth{
-			Username: username,
-			Password: password,
+			return tagger, fmt.Errorf("invalid SOURCE_DATE_EPOCH %q: %w", epoch, err)
 		}
+		tagger.When = time.Unix(seconds, 0).UTC()
 	}
-	return auth, err
+	return tagger, nil
 }
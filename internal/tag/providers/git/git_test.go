@@ -0,0 +1,218 @@
+package git
+
+import (
+	"context"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/sanjP10/release/internal/tag"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestCreateTagObjectTagger(t *testing.T) {
+	assertTest := assert.New(t)
+	repository, hash := initTestRepository(t)
+	repo := Properties{Username: "x-token-auth", Email: "user@example.com", TaggerName: "Release Bot", TaggerEmail: "bot@example.com",
+		RepoProperties: tag.RepoProperties{Tag: "1.0.0", Hash: hash.String(), Body: "notes"}}
+	repo.repository = repository
+	before := time.Now().Add(-time.Second)
+	assertTest.NoError(repo.createTagObject())
+
+	tagRef, err := repository.Tag("1.0.0")
+	assertTest.NoError(err)
+	tagObject, err := repository.TagObject(tagRef.Hash())
+	assertTest.NoError(err)
+	assertTest.Equal("1.0.0", tagObject.Name)
+	assertTest.Equal("notes\n", tagObject.Message)
+	assertTest.Equal(hash, tagObject.Target)
+	assertTest.Equal("Release Bot", tagObject.Tagger.Name)
+	assertTest.Equal("bot@example.com", tagObject.Tagger.Email)
+	assertTest.True(tagObject.Tagger.When.After(before))
+}
+
+func TestCreateTagObjectTaggerDefaults(t *testing.T) {
+	assertTest := assert.New(t)
+	repository, hash := initTestRepository(t)
+	repo := Properties{Username: "tester", Email: "tester@example.com",
+		RepoProperties: tag.RepoProperties{Tag: "1.0.0", Hash: hash.String(), Body: "notes"}}
+	repo.repository = repository
+	assertTest.NoError(repo.createTagObject())
+
+	tagRef, _ := repository.Tag("1.0.0")
+	tagObject, err := repository.TagObject(tagRef.Hash())
+	assertTest.NoError(err)
+	assertTest.Equal("tester", tagObject.Tagger.Name)
+	assertTest.Equal("tester@example.com", tagObject.Tagger.Email)
+}
+
+func TestCreateTagObjectSourceDateEpoch(t *testing.T) {
+	t.Setenv("SOURCE_DATE_EPOCH", "1700000000")
+	assertTest := assert.New(t)
+	repository, hash := initTestRepository(t)
+	repo := Properties{Username: "tester", Email: "tester@example.com",
+		RepoProperties: tag.RepoProperties{Tag: "1.0.0", Hash: hash.String(), Body: "notes"}}
+	repo.repository = repository
+	assertTest.NoError(repo.createTagObject())
+
+	tagRef, _ := repository.Tag("1.0.0")
+	tagObject, err := repository.TagObject(tagRef.Hash())
+	assertTest.NoError(err)
+	assertTest.Equal(int64(1700000000), tagObject.Tagger.When.Unix())
+}
+
+func TestCreateTagObjectInvalidSourceDateEpoch(t *testing.T) {
+	t.Setenv("SOURCE_DATE_EPOCH", "yesterday")
+	repository, hash := initTestRepository(t)
+	repo := Properties{Username: "tester", Email: "tester@example.com",
+		RepoProperties: tag.RepoProperties{Tag: "1.0.0", Hash: hash.String(), Body: "notes"}}
+	repo.repository = repository
+	assert.Error(t, repo.createTagObject())
+}
+
+// initTestRemote creates a bare origin and a local clone with a single commit pushed to it
+func initTestRemote(t *testing.T) (string, string, plumbing.Hash) {
+	originPath := t.TempDir()
+	clonePath := t.TempDir()
+	_, err := git.PlainInit(originPath, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clone, err := git.PlainInit(clonePath, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	worktree, _ := clone.Worktree()
+	hash, err := worktree.Commit("initial commit", &git.CommitOptions{
+		AllowEmptyCommits: true,
+		Author:            &object.Signature{Name: "tester", Email: "tester@example.com", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = clone.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{originPath}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = clone.Push(&git.PushOptions{RemoteName: "origin", RefSpecs: []config.RefSpec{"refs/heads/*:refs/heads/*"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return originPath, clonePath, hash
+}
+
+func TestCreateTagWithRepoPath(t *testing.T) {
+	assertTest := assert.New(t)
+	originPath, clonePath, hash := initTestRemote(t)
+	repo := Properties{RepoPath: clonePath, Email: "tester@example.com", Username: "tester",
+		RepoProperties: tag.RepoProperties{Tag: "1.0.0", Hash: hash.String(), Body: "notes"}}
+	assertTest.NoError(repo.InitializeRepository())
+	assertTest.True(repo.ValidateTag().TagDoesntExist)
+	assertTest.True(repo.CreateTag())
+
+	// the pushed tag is visible from a fresh in memory repository using only the origin
+	repo = Properties{Origin: originPath, RepoProperties: tag.RepoProperties{Tag: "1.0.0", Hash: hash.String()}}
+	assertTest.NoError(repo.InitializeRepository())
+	results := repo.ValidateTag()
+	assertTest.False(results.TagDoesntExist)
+	assertTest.True(results.TagExistsWithProvidedHash)
+
+	repo.Hash = "not_hash"
+	results = repo.ValidateTag()
+	assertTest.False(results.TagDoesntExist)
+	assertTest.False(results.TagExistsWithProvidedHash)
+}
+
+func TestCreateTagWithoutRepoPath(t *testing.T) {
+	assertTest := assert.New(t)
+	originPath, _, hash := initTestRemote(t)
+	repo := Properties{Origin: originPath, Email: "tester@example.com", Username: "tester",
+		RepoProperties: tag.RepoProperties{Tag: "1.0.0", Hash: hash.String(), Body: "notes"}}
+	assertTest.NoError(repo.InitializeRepository())
+	assertTest.True(repo.CreateTag())
+	assertTest.NoError(repo.InitializeRepository())
+	assertTest.True(repo.ValidateTag().TagExistsWithProvidedHash)
+}
+
+func TestCreateTagCancelled(t *testing.T) {
+	assertTest := assert.New(t)
+	originPath, _, hash := initTestRemote(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	repo := Properties{Origin: originPath, Email: "tester@example.com", Username: "tester",
+		RepoProperties: tag.RepoProperties{Tag: "1.0.0", Hash: hash.String(), Body: "notes", Context: ctx}}
+	assertTest.NoError(repo.InitializeRepository())
+	cancel()
+	assertTest.False(repo.CreateTag())
+	assertTest.ErrorContains(repo.Failure(), "context canceled")
+
+	// nothing was pushed to the origin
+	repo = Properties{Origin: originPath, RepoProperties: tag.RepoProperties{Tag: "1.0.0", Hash: hash.String()}}
+	assertTest.NoError(repo.InitializeRepository())
+	assertTest.True(repo.ValidateTag().TagDoesntExist)
+}
+
+func TestInitializeRepositoryMissingOrigin(t *testing.T) {
+	repo := Properties{RepoProperties: tag.RepoProperties{Tag: "1.0.0", Hash: "hash"}}
+	assert.Error(t, repo.InitializeRepository())
+}
+
+func TestValidateTagPeeledReference(t *testing.T) {
+	assertTest := assert.New(t)
+	repository, _ := initTestRepository(t)
+	repo := Properties{RepoProperties: tag.RepoProperties{Tag: "1.0.0", Hash: "2222222222222222222222222222222222222222"}}
+	repo.repository = repository
+	// the tag object is not available locally, the advertised peeled reference is enough to compare
+	repo.remoteRefs = []*plumbing.Reference{
+		plumbing.NewHashReference("refs/tags/1.0.0", plumbing.NewHash("1111111111111111111111111111111111111111")),
+		plumbing.NewHashReference("refs/tags/1.0.0^{}", plumbing.NewHash("2222222222222222222222222222222222222222")),
+	}
+	results := repo.ValidateTag()
+	assertTest.False(results.TagDoesntExist)
+	assertTest.True(results.TagExistsWithProvidedHash)
+
+	repo.Hash = "1111111111111111111111111111111111111111"
+	results = repo.ValidateTag()
+	assertTest.False(results.TagDoesntExist)
+	assertTest.False(results.TagExistsWithProvidedHash)
+
+	repo.Tag = "2.0.0"
+	results = repo.ValidateTag()
+	assertTest.True(results.TagDoesntExist)
+	assertTest.False(results.TagExistsWithProvidedHash)
+}
+
+func TestLightweightTag(t *testing.T) {
+	assertTest := assert.New(t)
+	originPath, _, hash := initTestRemote(t)
+	repo := Properties{Origin: originPath, Lightweight: true,
+		RepoProperties: tag.RepoProperties{Tag: "1.0.0", Hash: hash.String()}}
+	assertTest.NoError(repo.InitializeRepository())
+	assertTest.True(repo.CreateTag())
+
+	repo = Properties{Origin: originPath, RepoProperties: tag.RepoProperties{Tag: "1.0.0", Hash: hash.String()}}
+	assertTest.NoError(repo.InitializeRepository())
+	tagRef := repo.remoteTag()
+	assertTest.NotNil(tagRef)
+	assertTest.Equal(hash, tagRef.Hash())
+	results := repo.ValidateTag()
+	assertTest.False(results.TagDoesntExist)
+	assertTest.True(results.TagExistsWithProvidedHash)
+
+	// a lightweight tag at another commit is fetched and peeled to the commit without panicking
+	repo.Hash = "1111111111111111111111111111111111111111"
+	results = repo.ValidateTag()
+	assertTest.False(results.TagDoesntExist)
+	assertTest.False(results.TagExistsWithProvidedHash)
+}
+
+func TestVerifyLightweightTag(t *testing.T) {
+	repository, hash := initTestRepository(t)
+	_, publicPath := writeOpenPGPKeys(t, t.TempDir())
+	repo := Properties{Lightweight: true, RepoProperties: tag.RepoProperties{Tag: "1.0.0", Hash: hash.String()}}
+	repo.repository = repository
+	assert.NoError(t, repo.createTagObject())
+	advertiseTag(t, &repo, "1.0.0")
+	assert.Error(t, repo.VerifyTag(publicPath))
+}
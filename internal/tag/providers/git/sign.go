@@ -0,0 +1,410 @@
+package git
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"golang.org/x/crypto/ssh"
+	"io"
+	"os"
+	"path"
+	"strings"
+)
+
+// Signing formats supported for tags
+const (
+	SignFormatOpenPGP = "openpgp"
+	SignFormatSSH     = "ssh"
+)
+
+const (
+	sshSigMagic     = "SSHSIG"
+	sshSigVersion   = 1
+	sshSigNamespace = "git"
+	sshSigHash      = "sha512"
+	sshSigArmorHead = "-----BEGIN SSH SIGNATURE-----"
+	sshSigArmorTail = "-----END SSH SIGNATURE-----"
+)
+
+// ValidSignFormat checks the signing format from the cli is supported
+func ValidSignFormat(format string) bool {
+	switch strings.ToLower(format) {
+	case "", SignFormatOpenPGP, SignFormatSSH:
+		return true
+	}
+	return false
+}
+
+// readOpenPGPKey reads an armored OpenPGP private key and decrypts it with the passphrase if required
+func readOpenPGPKey(filePath string, passphrase string) (*openpgp.Entity, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	entities, err := openpgp.ReadArmoredKeyRing(file)
+	if err != nil {
+		return nil, err
+	}
+	if len(entities) == 0 || entities[0].PrivateKey == nil {
+		return nil, errors.New("no private key found in " + filePath)
+	}
+	entity := entities[0]
+	if entity.PrivateKey.Encrypted || hasEncryptedSubkey(entity) {
+		if passphrase == "" {
+			return nil, errors.New("signing key is encrypted, please provide the signing key password")
+		}
+		err = entity.DecryptPrivateKeys([]byte(passphrase))
+		if err != nil {
+			return nil, fmt.Errorf("unable to decrypt signing key: %w", err)
+		}
+	}
+	return entity, nil
+}
+
+func hasEncryptedSubkey(entity *openpgp.Entity) bool {
+	for _, subkey := range entity.Subkeys {
+		if subkey.PrivateKey != nil && subkey.PrivateKey.Encrypted {
+			return true
+		}
+	}
+	return false
+}
+
+// readSSHSigner reads an OpenSSH private key used for signing
+func readSSHSigner(filePath string, passphrase string) (ssh.Signer, error) {
+	pem, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	if passphrase != "" {
+		return ssh.ParsePrivateKeyWithPassphrase(pem, []byte(passphrase))
+	}
+	return ssh.ParsePrivateKey(pem)
+}
+
+// createSSHSignedTag creates an annotated tag object signed with an ssh key and the reference pointing at it
+func (r *Properties) createSSHSignedTag(name string, hash plumbing.Hash, tagger object.Signature, message string, signer ssh.Signer) (*plumbing.Reference, error) {
+	refName := plumbing.NewTagReferenceName(name)
+	rawObject, err := object.GetObject(r.repository.Storer, hash)
+	if err != nil {
+		return nil, err
+	}
+	tagObject := &object.Tag{
+		Name:       name,
+		Tagger:     tagger,
+		Message:    strings.TrimSpace(message) + "\n",
+		TargetType: rawObject.Type(),
+		Target:     hash,
+	}
+	unsigned := &plumbing.MemoryObject{}
+	err = tagObject.EncodeWithoutSignature(unsigned)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := unsigned.Reader()
+	if err != nil {
+		return nil, err
+	}
+	payload, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := sshSign(signer, payload)
+	if err != nil {
+		return nil, err
+	}
+	tagObject.PGPSignature = signature
+
+	encoded := r.repository.Storer.NewEncodedObject()
+	err = tagObject.Encode(encoded)
+	if err != nil {
+		return nil, err
+	}
+	target, err := r.repository.Storer.SetEncodedObject(encoded)
+	if err != nil {
+		return nil, err
+	}
+	ref := plumbing.NewHashReference(refName, target)
+	return ref, r.repository.Storer.SetReference(ref)
+}
+
+// sshSign produces an armored ssh signature of the payload in the format used by git and ssh-keygen -Y sign
+func sshSign(signer ssh.Signer, payload []byte) (string, error) {
+	digest := sha512.Sum512(payload)
+	signedData := sshSignedData(sshSigNamespace, sshSigHash, digest[:])
+
+	var signature *ssh.Signature
+	var err error
+	if algorithmSigner, ok := signer.(ssh.AlgorithmSigner); ok && signer.PublicKey().Type() == ssh.KeyAlgoRSA {
+		// ssh-rsa signatures use sha1 and are rejected by git, use rsa-sha2-512 instead
+		signature, err = algorithmSigner.SignWithAlgorithm(rand.Reader, signedData, ssh.KeyAlgoRSASHA512)
+	} else {
+		signature, err = signer.Sign(rand.Reader, signedData)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	blob := &bytes.Buffer{}
+	blob.WriteString(sshSigMagic)
+	_ = binary.Write(blob, binary.BigEndian, uint32(sshSigVersion))
+	writeSSHString(blob, signer.PublicKey().Marshal())
+	writeSSHString(blob, []byte(sshSigNamespace))
+	writeSSHString(blob, nil)
+	writeSSHString(blob, []byte(sshSigHash))
+	writeSSHString(blob, ssh.Marshal(signature))
+
+	encoded := base64.StdEncoding.EncodeToString(blob.Bytes())
+	armored := &strings.Builder{}
+	armored.WriteString(sshSigArmorHead + "\n")
+	for len(encoded) > 70 {
+		armored.WriteString(encoded[:70] + "\n")
+		encoded = encoded[70:]
+	}
+	armored.WriteString(encoded + "\n")
+	armored.WriteString(sshSigArmorTail + "\n")
+	return armored.String(), nil
+}
+
+// sshVerify checks an armored ssh signature of the payload was made by one of the allowed keys
+func sshVerify(armored string, payload []byte, allowedKeys []ssh.PublicKey) error {
+	body := strings.TrimSpace(armored)
+	if !strings.HasPrefix(body, sshSigArmorHead) || !strings.HasSuffix(body, sshSigArmorTail) {
+		return errors.New("malformed ssh signature")
+	}
+	body = strings.TrimSuffix(strings.TrimPrefix(body, sshSigArmorHead), sshSigArmorTail)
+	blob, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(body), ""))
+	if err != nil {
+		return fmt.Errorf("malformed ssh signature: %w", err)
+	}
+	if !bytes.HasPrefix(blob, []byte(sshSigMagic)) || len(blob) < len(sshSigMagic)+4 {
+		return errors.New("malformed ssh signature")
+	}
+	rest := blob[len(sshSigMagic):]
+	if binary.BigEndian.Uint32(rest) != sshSigVersion {
+		return errors.New("unsupported ssh signature version")
+	}
+	rest = rest[4:]
+	fields := make([][]byte, 5)
+	for i := range fields {
+		fields[i], rest, err = readSSHString(rest)
+		if err != nil {
+			return err
+		}
+	}
+	publicKeyBlob, namespace, hashAlgorithm, signatureBlob := fields[0], fields[1], fields[3], fields[4]
+	if string(namespace) != sshSigNamespace {
+		return fmt.Errorf("unexpected ssh signature namespace %q", namespace)
+	}
+
+	var digest []byte
+	switch string(hashAlgorithm) {
+	case "sha512":
+		sum := sha512.Sum512(payload)
+		digest = sum[:]
+	case "sha256":
+		sum := sha256.Sum256(payload)
+		digest = sum[:]
+	default:
+		return fmt.Errorf("unsupported ssh signature hash algorithm %q", hashAlgorithm)
+	}
+
+	publicKey, err := ssh.ParsePublicKey(publicKeyBlob)
+	if err != nil {
+		return err
+	}
+	allowed := false
+	for _, key := range allowedKeys {
+		if bytes.Equal(key.Marshal(), publicKey.Marshal()) {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return errors.New("tag signed with unknown key " + ssh.FingerprintSHA256(publicKey))
+	}
+
+	signature := &ssh.Signature{}
+	err = ssh.Unmarshal(signatureBlob, signature)
+	if err != nil {
+		return err
+	}
+	return publicKey.Verify(sshSignedData(string(namespace), string(hashAlgorithm), digest), signature)
+}
+
+// VerifyTag verifies the signature of the tag against an armored OpenPGP keyring or ssh public keys file
+func (r *Properties) VerifyTag(keyringPath string) error {
+	keyring, err := os.ReadFile(keyringPath)
+	if err != nil {
+		return err
+	}
+	tagRef := r.remoteTag()
+	if tagRef == nil {
+		return errors.New("tag " + r.Tag + " not found")
+	}
+	rawObject, err := r.loadTag(tagRef)
+	if err != nil {
+		return err
+	}
+	if rawObject.Type() != plumbing.TagObject {
+		return errors.New("tag " + r.Tag + " is a lightweight tag and cannot be signed")
+	}
+	tagObject, err := object.DecodeTag(r.repository.Storer, rawObject)
+	if err != nil {
+		return err
+	}
+	if tagObject.PGPSignature == "" {
+		return errors.New("tag " + r.Tag + " is not signed")
+	}
+	if strings.HasPrefix(tagObject.PGPSignature, sshSigArmorHead) {
+		allowedKeys, err := parseSSHPublicKeys(keyring)
+		if err != nil {
+			return err
+		}
+		unsigned := &plumbing.MemoryObject{}
+		err = tagObject.EncodeWithoutSignature(unsigned)
+		if err != nil {
+			return err
+		}
+		reader, err := unsigned.Reader()
+		if err != nil {
+			return err
+		}
+		payload, err := io.ReadAll(reader)
+		if err != nil {
+			return err
+		}
+		return sshVerify(tagObject.PGPSignature, payload, allowedKeys)
+	}
+	_, err = tagObject.Verify(string(keyring))
+	return err
+}
+
+// parseSSHPublicKeys parses public keys in authorized_keys or allowed signers format, keys of allowed signers limited
+// to other namespaces or that are certificate authorities are left out
+func parseSSHPublicKeys(data []byte) ([]ssh.PublicKey, error) {
+	var keys []ssh.PublicKey
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, options, err := parseAllowedSigner(line)
+		if err != nil {
+			return nil, err
+		}
+		if allowedForGit(options) {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no ssh public keys found")
+	}
+	return keys, nil
+}
+
+// parseAllowedSigner parses a line of principals, optional options, the key type and key, the principals are
+// omitted in authorized_keys files
+func parseAllowedSigner(line string) (ssh.PublicKey, []string, error) {
+	fields := splitUnquoted(line, func(r rune) bool { return r == ' ' || r == '\t' })
+	for i := 0; i+1 < len(fields); i++ {
+		key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(fields[i] + " " + fields[i+1]))
+		if err != nil || key.Type() != fields[i] {
+			continue
+		}
+		var options []string
+		switch {
+		case i == 2:
+			options = splitUnquoted(fields[1], func(r rune) bool { return r == ',' })
+		case i == 1 && !strings.Contains(fields[0], "@") && (strings.Contains(fields[0], "=") || fields[0] == "cert-authority"):
+			// authorized_keys options without principals
+			options = splitUnquoted(fields[0], func(r rune) bool { return r == ',' })
+		case i > 2:
+			return nil, nil, errors.New("invalid allowed signers line " + line)
+		}
+		return key, options, nil
+	}
+	return nil, nil, errors.New("no ssh public key in line " + line)
+}
+
+// allowedForGit checks the options allow verifying git signatures with a plain key
+func allowedForGit(options []string) bool {
+	for _, option := range options {
+		name, value, _ := strings.Cut(option, "=")
+		switch strings.ToLower(name) {
+		case "cert-authority":
+			return false
+		case "namespaces":
+			allowed := false
+			for _, namespace := range strings.Split(strings.Trim(value, "\""), ",") {
+				if matched, _ := path.Match(strings.TrimSpace(namespace), sshSigNamespace); matched {
+					allowed = true
+				}
+			}
+			if !allowed {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// splitUnquoted splits on the separators outside double quotes, empty fields are dropped
+func splitUnquoted(value string, separator func(rune) bool) []string {
+	var fields []string
+	var current strings.Builder
+	quoted := false
+	for _, r := range value {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			current.WriteRune(r)
+		case !quoted && separator(r):
+			if current.Len() > 0 {
+				fields = append(fields, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if current.Len() > 0 {
+		fields = append(fields, current.String())
+	}
+	return fields
+}
+
+func sshSignedData(namespace string, hashAlgorithm string, digest []byte) []byte {
+	data := &bytes.Buffer{}
+	data.WriteString(sshSigMagic)
+	writeSSHString(data, []byte(namespace))
+	writeSSHString(data, nil)
+	writeSSHString(data, []byte(hashAlgorithm))
+	writeSSHString(data, digest)
+	return data.Bytes()
+}
+
+func writeSSHString(buffer *bytes.Buffer, value []byte) {
+	_ = binary.Write(buffer, binary.BigEndian, uint32(len(value)))
+	buffer.Write(value)
+}
+
+func readSSHString(data []byte) ([]byte, []byte, error) {
+	if len(data) < 4 {
+		return nil, nil, errors.New("malformed ssh signature")
+	}
+	length := binary.BigEndian.Uint32(data)
+	if uint32(len(data)-4) < length {
+		return nil, nil, errors.New("malformed ssh signature")
+	}
+	return data[4 : 4+length], data[4+length:], nil
+}
@@ -0,0 +1,179 @@
+package git
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/sanjP10/release/internal/tag"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/ssh"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// initTestRepository creates an in memory repository with a single commit and returns it with the hash of the commit
+func initTestRepository(t *testing.T) (*git.Repository, plumbing.Hash) {
+	repository, err := git.Init(memory.NewStorage(), memfs.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	worktree, err := repository.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash, err := worktree.Commit("initial commit", &git.CommitOptions{
+		AllowEmptyCommits: true,
+		Author:            &object.Signature{Name: "tester", Email: "tester@example.com", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return repository, hash
+}
+
+// advertiseTag makes a locally created tag appear as if it was listed by the origin
+func advertiseTag(t *testing.T, repo *Properties, name string) {
+	tagRef, err := repo.repository.Tag(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo.remoteRefs = []*plumbing.Reference{tagRef}
+}
+
+func writeSSHKeys(t *testing.T, dir string) (string, string) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := ssh.MarshalPrivateKey(privateKey, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sshPublicKey, err := ssh.NewPublicKey(publicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privatePath := filepath.Join(dir, "id_ed25519")
+	publicPath := filepath.Join(dir, "id_ed25519.pub")
+	assert.NoError(t, os.WriteFile(privatePath, pem.EncodeToMemory(block), 0600))
+	assert.NoError(t, os.WriteFile(publicPath, ssh.MarshalAuthorizedKey(sshPublicKey), 0600))
+	return privatePath, publicPath
+}
+
+func writeOpenPGPKeys(t *testing.T, dir string) (string, string) {
+	entity, err := openpgp.NewEntity("tester", "", "tester@example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privatePath := filepath.Join(dir, "private.asc")
+	publicPath := filepath.Join(dir, "public.asc")
+	privateFile, _ := os.Create(privatePath)
+	writer, _ := armor.Encode(privateFile, openpgp.PrivateKeyType, nil)
+	assert.NoError(t, entity.SerializePrivate(writer, nil))
+	writer.Close()
+	privateFile.Close()
+	publicFile, _ := os.Create(publicPath)
+	writer, _ = armor.Encode(publicFile, openpgp.PublicKeyType, nil)
+	assert.NoError(t, entity.Serialize(writer))
+	writer.Close()
+	publicFile.Close()
+	return privatePath, publicPath
+}
+
+func TestSSHSignAndVerify(t *testing.T) {
+	assertTest := assert.New(t)
+	privatePath, publicPath := writeSSHKeys(t, t.TempDir())
+	signer, err := readSSHSigner(privatePath, "")
+	assertTest.NoError(err)
+	publicKeys, _ := os.ReadFile(publicPath)
+	allowedKeys, err := parseSSHPublicKeys(publicKeys)
+	assertTest.NoError(err)
+
+	signature, err := sshSign(signer, []byte("payload"))
+	assertTest.NoError(err)
+	assertTest.NoError(sshVerify(signature, []byte("payload"), allowedKeys))
+	assertTest.Error(sshVerify(signature, []byte("tampered"), allowedKeys))
+
+	_, otherPublicPath := writeSSHKeys(t, t.TempDir())
+	otherKeys, _ := os.ReadFile(otherPublicPath)
+	otherAllowedKeys, _ := parseSSHPublicKeys(otherKeys)
+	assertTest.Error(sshVerify(signature, []byte("payload"), otherAllowedKeys))
+}
+
+func TestCreateTagObjectSignedSSH(t *testing.T) {
+	assertTest := assert.New(t)
+	repository, hash := initTestRepository(t)
+	privatePath, publicPath := writeSSHKeys(t, t.TempDir())
+	repo := Properties{Username: "tester", Email: "tester@example.com", SignKey: privatePath, SignFormat: SignFormatSSH,
+		RepoProperties: tag.RepoProperties{Tag: "1.0.0", Hash: hash.String(), Body: "notes"}}
+	repo.repository = repository
+	assertTest.NoError(repo.createTagObject())
+	advertiseTag(t, &repo, "1.0.0")
+	assertTest.NoError(repo.VerifyTag(publicPath))
+
+	_, otherPublicPath := writeSSHKeys(t, t.TempDir())
+	assertTest.Error(repo.VerifyTag(otherPublicPath))
+}
+
+func TestCreateTagObjectSignedOpenPGP(t *testing.T) {
+	assertTest := assert.New(t)
+	repository, hash := initTestRepository(t)
+	privatePath, publicPath := writeOpenPGPKeys(t, t.TempDir())
+	repo := Properties{Username: "tester", Email: "tester@example.com", SignKey: privatePath,
+		RepoProperties: tag.RepoProperties{Tag: "1.0.0", Hash: hash.String(), Body: "notes"}}
+	repo.repository = repository
+	assertTest.NoError(repo.createTagObject())
+	advertiseTag(t, &repo, "1.0.0")
+	assertTest.NoError(repo.VerifyTag(publicPath))
+
+	_, otherPublicPath := writeOpenPGPKeys(t, t.TempDir())
+	assertTest.Error(repo.VerifyTag(otherPublicPath))
+}
+
+func TestVerifyTagUnsigned(t *testing.T) {
+	assertTest := assert.New(t)
+	repository, hash := initTestRepository(t)
+	_, publicPath := writeOpenPGPKeys(t, t.TempDir())
+	repo := Properties{Username: "tester", Email: "tester@example.com",
+		RepoProperties: tag.RepoProperties{Tag: "1.0.0", Hash: hash.String(), Body: "notes"}}
+	repo.repository = repository
+	assertTest.NoError(repo.createTagObject())
+	advertiseTag(t, &repo, "1.0.0")
+	assertTest.Error(repo.VerifyTag(publicPath))
+}
+
+func TestParseSSHPublicKeysAllowedSigners(t *testing.T) {
+	assertTest := assert.New(t)
+	_, publicPath := writeSSHKeys(t, t.TempDir())
+	publicKey, _ := os.ReadFile(publicPath)
+	key := strings.TrimSpace(string(publicKey))
+	_, otherPublicPath := writeSSHKeys(t, t.TempDir())
+	otherKey, _ := os.ReadFile(otherPublicPath)
+
+	allowedSigners := "# allowed signers\n" +
+		"tester@example.com,\"release bot\"@example.com namespaces=\"file,git\",valid-after=\"20200101\" " + key + " tester\n" +
+		"ci@example.com\tnamespaces=\"file\" " + strings.TrimSpace(string(otherKey)) + "\n"
+	keys, err := parseSSHPublicKeys([]byte(allowedSigners))
+	assertTest.NoError(err)
+	assertTest.Len(keys, 1)
+	assertTest.Equal(key, strings.TrimSpace(string(ssh.MarshalAuthorizedKey(keys[0]))))
+
+	keys, err = parseSSHPublicKeys([]byte("tester@example.com " + key + "\n" + "no-pty " + key))
+	assertTest.NoError(err)
+	assertTest.Len(keys, 2)
+
+	_, err = parseSSHPublicKeys([]byte("tester@example.com cert-authority " + key))
+	assertTest.Error(err)
+	_, err = parseSSHPublicKeys([]byte("tester@example.com namespaces=\"git\""))
+	assertTest.Error(err)
+}
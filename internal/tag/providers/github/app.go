@@ -0,0 +1,172 @@
+package github
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Authentication types for the GitHub API
+const (
+	AuthBasic  = "basic"
+	AuthBearer = "bearer"
+	AuthApp    = "app"
+)
+
+// appJWTLifetime is below the ten minutes GitHub allows for an app JWT
+const appJWTLifetime = 9 * time.Minute
+
+// ValidAuthType checks the authentication type from the cli is supported
+func ValidAuthType(authType string) bool {
+	switch strings.ToLower(authType) {
+	case "", AuthBasic, AuthBearer, AuthApp:
+		return true
+	}
+	return false
+}
+
+// Installation structure of the GitHub App installation response
+type Installation struct {
+	ID int64 `json:"id"`
+}
+
+// InstallationToken structure of the GitHub App installation access token response
+type InstallationToken struct {
+	Token string `json:"token"`
+}
+
+// authorize sets the credentials of the authentication type on the request
+func (r *Properties) authorize(request *http.Request) error {
+	switch strings.ToLower(r.AuthType) {
+	case AuthApp:
+		if r.installationToken == "" {
+			token, err := r.appInstallationToken()
+			if err != nil {
+				return err
+			}
+			r.installationToken = token
+		}
+		request.Header.Set("Authorization", "Bearer "+r.installationToken)
+	case AuthBearer:
+		request.Header.Set("Authorization", "Bearer "+r.Password)
+	default:
+		request.SetBasicAuth(r.Username, r.Password)
+	}
+	return nil
+}
+
+// appInstallationToken signs a JWT with the app private key and exchanges it for an installation access token
+func (r *Properties) appInstallationToken() (string, error) {
+	key, err := readAppKey(r.AppKey)
+	if err != nil {
+		return "", err
+	}
+	jwt, err := appJWT(r.AppID, key, time.Now())
+	if err != nil {
+		return "", err
+	}
+	installationID := r.InstallationID
+	if installationID == "" {
+		installation := Installation{}
+		err = r.appRequest("GET", fmt.Sprintf("%s/repos/%s/installation", r.apiURL(), r.Repo), jwt, http.StatusOK, &installation)
+		if err != nil {
+			return "", fmt.Errorf("unable to find the app installation for %s, check the app is installed on the repository: %w", r.Repo, err)
+		}
+		installationID = strconv.FormatInt(installation.ID, 10)
+	}
+	token := InstallationToken{}
+	err = r.appRequest("POST", fmt.Sprintf("%s/app/installations/%s/access_tokens", r.apiURL(), installationID), jwt, http.StatusCreated, &token)
+	if err != nil {
+		return "", fmt.Errorf("unable to create an installation token for installation %s: %w", installationID, err)
+	}
+	if token.Token == "" {
+		return "", errors.New("no installation token in response")
+	}
+	return token.Token, nil
+}
+
+// appRequest calls an app endpoint authenticated with the JWT and decodes the response
+func (r *Properties) appRequest(method string, url string, jwt string, expectedStatus int, result interface{}) error {
+	request, err := http.NewRequestWithContext(r.RequestContext(), method, url, nil)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Authorization", "Bearer "+jwt)
+	request.Header.Set("Accept", "application/vnd.github+json")
+	resp, err := r.HTTPClient().Do(request)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != expectedStatus {
+		res := BadResponse{}
+		_ = json.Unmarshal(body, &res)
+		return fmt.Errorf("status %d: %s", resp.StatusCode, res.Message)
+	}
+	return json.Unmarshal(body, result)
+}
+
+// readAppKey reads the PEM encoded RSA private key downloaded for the app
+func readAppKey(filePath string) (*rsa.PrivateKey, error) {
+	pemBytes, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, errors.New("no PEM encoded private key found in " + filePath)
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse app private key %s: %w", filePath, err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("app private key " + filePath + " is not an RSA key")
+	}
+	return key, nil
+}
+
+// appJWT creates the RS256 signed JWT identifying the app, issued a minute early to allow for clock drift
+func appJWT(appID string, key *rsa.PrivateKey, now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]interface{}{
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(appJWTLifetime).Unix(),
+		"iss": appID,
+	})
+	if err != nil {
+		return "", err
+	}
+	encoding := base64.RawURLEncoding
+	unsigned := encoding.EncodeToString(header) + "." + encoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return unsigned + "." + encoding.EncodeToString(signature), nil
+}
@@ -0,0 +1,123 @@
+package github
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"github.com/sanjP10/release/internal/tag"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeAppKey(t *testing.T) (string, *rsa.PrivateKey) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPath := filepath.Join(t.TempDir(), "app.pem")
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	err = os.WriteFile(keyPath, pemBytes, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keyPath, key
+}
+
+func TestAppJWT(t *testing.T) {
+	assertTest := assert.New(t)
+	_, key := writeAppKey(t)
+	now := time.Unix(1700000000, 0)
+	jwt, err := appJWT("12345", key, now)
+	assertTest.NoError(err)
+
+	parts := strings.Split(jwt, ".")
+	assertTest.Len(parts, 3)
+	claimsJSON, err := base64.RawURLEncoding.DecodeString(parts[1])
+	assertTest.NoError(err)
+	claims := map[string]interface{}{}
+	assertTest.NoError(json.Unmarshal(claimsJSON, &claims))
+	assertTest.Equal("12345", claims["iss"])
+	assertTest.Equal(float64(now.Add(-time.Minute).Unix()), claims["iat"])
+	assertTest.Equal(float64(now.Add(appJWTLifetime).Unix()), claims["exp"])
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	assertTest.NoError(err)
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	assertTest.NoError(rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], signature))
+}
+
+func TestReadAppKeyInvalid(t *testing.T) {
+	keyPath := filepath.Join(t.TempDir(), "app.pem")
+	assert.NoError(t, os.WriteFile(keyPath, []byte("not a key"), 0600))
+	_, err := readAppKey(keyPath)
+	assert.Error(t, err)
+}
+
+func TestCreateTagAsApp(t *testing.T) {
+	keyPath, _ := writeAppKey(t)
+	body := Release{TargetCommitish: "hash", Body: "hello", TagName: "tag", Name: "tag"}
+	defer gock.Off() // Flush pending mocks after test execution
+
+	gock.New("https://api.github.com").
+		Get("/repos/repo/installation").
+		HeaderPresent("Authorization").
+		Reply(http.StatusOK).
+		JSON(Installation{ID: 42})
+	gock.New("https://api.github.com").
+		Post("/app/installations/42/access_tokens").
+		Reply(http.StatusCreated).
+		JSON(InstallationToken{Token: "installation-token"})
+	gock.New("https://api.github.com").
+		Get("/repos/repo/git/refs/tags/tag").
+		MatchHeader("Authorization", "^Bearer installation-token$").
+		Reply(http.StatusNotFound)
+	gock.New("https://api.github.com").
+		Post("/repos/repo/releases").
+		MatchHeader("Authorization", "^Bearer installation-token$").
+		Reply(http.StatusCreated).
+		JSON(body)
+	assertTest := assert.New(t)
+	repo := Properties{Repo: "repo", AuthType: AuthApp, AppID: "12345", AppKey: keyPath,
+		RepoProperties: tag.RepoProperties{Tag: "tag", Hash: "hash", Body: "hello"}}
+	assertTest.True(repo.CreateTag())
+	assertTest.True(gock.IsDone())
+}
+
+func TestValidateTagAppNotInstalled(t *testing.T) {
+	keyPath, _ := writeAppKey(t)
+	defer gock.Off() // Flush pending mocks after test execution
+
+	gock.New("https://api.github.com").
+		Get("/repos/repo/installation").
+		Reply(http.StatusNotFound).
+		JSON(BadResponse{Message: "Not Found"})
+	assertTest := assert.New(t)
+	repo := Properties{Repo: "repo", AuthType: AuthApp, AppID: "12345", AppKey: keyPath,
+		RepoProperties: tag.RepoProperties{Tag: "tag", Hash: "hash"}}
+	results := repo.ValidateTag()
+	assertTest.False(results.TagDoesntExist)
+	assertTest.True(results.Unknown)
+	assertTest.Contains(results.Message, "installed")
+}
+
+func TestValidateTagBearer(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	gock.New("https://api.github.com").
+		Get("/repos/repo/git/refs/tags/tag").
+		MatchHeader("Authorization", "^Bearer token$").
+		Reply(http.StatusNotFound)
+	repo := Properties{Repo: "repo", AuthType: AuthBearer, RepoProperties: tag.RepoProperties{Password: "token", Tag: "tag", Hash: "hash"}}
+	assert.True(t, repo.ValidateTag().TagDoesntExist)
+}
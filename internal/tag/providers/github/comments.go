@@ -0,0 +1,76 @@
+package github
+
+import (
+	"fmt"
+	"github.com/sanjP10/release/internal/tag"
+	"net/http"
+	"strings"
+)
+
+// commentsPageSize is the number of issue comments requested per page
+const commentsPageSize = 100
+
+// IssueComment is a comment on an issue or pull request
+type IssueComment struct {
+	ID   int64  `json:"id,omitempty"`
+	Body string `json:"body"`
+}
+
+// UpsertComment updates the comment on the pull request starting with the marker, or adds a new comment
+func (r *Properties) UpsertComment(pullRequest int, marker string, body string) error {
+	id, err := r.findComment(pullRequest, marker)
+	if err != nil {
+		return err
+	}
+	method, url := "POST", fmt.Sprintf("%s/repos/%s/issues/%d/comments", r.apiURL(), r.Repo, pullRequest)
+	if id != 0 {
+		method, url = "PATCH", fmt.Sprintf("%s/repos/%s/issues/comments/%d", r.apiURL(), r.Repo, id)
+	}
+	request, err := tag.NewJSONRequest(r.RequestContext(), method, url, IssueComment{Body: body})
+	if err != nil {
+		return err
+	}
+	err = r.authorize(request)
+	if err != nil {
+		return err
+	}
+	resp, err := r.HTTPClient().Do(request)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return tag.CheckWriteResponse(resp)
+}
+
+// findComment pages through the comments of the pull request for the one starting with the marker, 0 when there is none
+func (r *Properties) findComment(pullRequest int, marker string) (int64, error) {
+	for page := 1; ; page++ {
+		url := fmt.Sprintf("%s/repos/%s/issues/%d/comments?per_page=%d&page=%d", r.apiURL(), r.Repo, pullRequest, commentsPageSize, page)
+		request, err := http.NewRequestWithContext(r.RequestContext(), "GET", url, nil)
+		if err != nil {
+			return 0, err
+		}
+		err = r.authorize(request)
+		if err != nil {
+			return 0, err
+		}
+		resp, err := r.HTTPClient().Do(request)
+		if err != nil {
+			return 0, err
+		}
+		var comments []IssueComment
+		err = tag.DecodeJSON(resp, &comments)
+		resp.Body.Close()
+		if err != nil {
+			return 0, fmt.Errorf("unable to list comments of pull request, %w", err)
+		}
+		for _, comment := range comments {
+			if strings.HasPrefix(comment.Body, marker) {
+				return comment.ID, nil
+			}
+		}
+		if len(comments) < commentsPageSize {
+			return 0, nil
+		}
+	}
+}
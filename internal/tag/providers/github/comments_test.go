@@ -0,0 +1,56 @@
+package github
+
+import (
+	"github.com/sanjP10/release/internal/tag"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+	"net/http"
+	"testing"
+)
+
+func TestUpsertCommentCreates(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	gock.New("https://api.github.com").
+		Get("/repos/repo/issues/3/comments").
+		MatchParam("page", "1").
+		Reply(http.StatusOK).
+		JSON([]IssueComment{{ID: 1, Body: "LGTM"}})
+	gock.New("https://api.github.com").
+		Post("/repos/repo/issues/3/comments").
+		JSON(IssueComment{Body: "<!-- marker -->\npreview"}).
+		Reply(http.StatusCreated).
+		JSON(IssueComment{ID: 2})
+	repo := Properties{Username: "user", Repo: "repo", RepoProperties: tag.RepoProperties{Password: "token"}}
+	assert.NoError(t, repo.UpsertComment(3, "<!-- marker -->", "<!-- marker -->\npreview"))
+	assert.True(t, gock.IsDone())
+}
+
+func TestUpsertCommentUpdates(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	gock.New("https://api.github.com").
+		Get("/repos/repo/issues/3/comments").
+		Reply(http.StatusOK).
+		JSON([]IssueComment{{ID: 2, Body: "> <!-- marker -->\nquoted"}, {ID: 1, Body: "<!-- marker -->\nold"}})
+	gock.New("https://api.github.com").
+		Patch("/repos/repo/issues/comments/1").
+		JSON(IssueComment{Body: "<!-- marker -->\nnew"}).
+		Reply(http.StatusOK).
+		JSON(IssueComment{ID: 1})
+	repo := Properties{Username: "user", Repo: "repo", RepoProperties: tag.RepoProperties{Password: "token"}}
+	assert.NoError(t, repo.UpsertComment(3, "<!-- marker -->", "<!-- marker -->\nnew"))
+	assert.True(t, gock.IsDone())
+}
+
+func TestUpsertCommentForbidden(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	gock.New("https://api.github.com").
+		Get("/repos/repo/issues/3/comments").
+		Reply(http.StatusForbidden).
+		JSON(BadResponse{Message: "Resource not accessible by integration"})
+	repo := Properties{Username: "user", Repo: "repo", RepoProperties: tag.RepoProperties{Password: "token"}}
+	err := repo.UpsertComment(3, "<!-- marker -->", "body")
+	assert.ErrorContains(t, err, "unable to list comments of pull request, status 403")
+}
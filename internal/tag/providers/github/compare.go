@@ -0,0 +1,56 @@
+package github
+
+import (
+	"fmt"
+	"github.com/sanjP10/release/internal/tag"
+	"net/http"
+)
+
+// compareFileLimit is the most files GitHub lists when comparing two commits
+const compareFileLimit = 300
+
+// ComparedFile is a file changed between two commits
+type ComparedFile struct {
+	Filename         string `json:"filename"`
+	PreviousFilename string `json:"previous_filename"`
+}
+
+// Comparison response of the compare API
+type Comparison struct {
+	Files []ComparedFile `json:"files"`
+}
+
+// ChangedFiles lists the files changed between the base tag and the hash with the compare API
+func (r *Properties) ChangedFiles(base string) ([]string, error) {
+	url := fmt.Sprintf("%s/repos/%s/compare/%s...%s", r.apiURL(), r.Repo, tag.EscapePath(base), r.Hash)
+	request, err := http.NewRequestWithContext(r.RequestContext(), "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	err = r.authorize(request)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := r.HTTPClient().Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	comparison := Comparison{}
+	err = tag.DecodeJSON(resp, &comparison)
+	if err != nil {
+		return nil, fmt.Errorf("unable to compare %s with %s, %w", base, r.Hash, err)
+	}
+	// files past the limit are left out, so the changes are incomplete
+	if len(comparison.Files) >= compareFileLimit {
+		return nil, fmt.Errorf("unable to compare %s with %s, GitHub lists at most %d changed files", base, r.Hash, compareFileLimit)
+	}
+	var files []string
+	for _, file := range comparison.Files {
+		files = append(files, file.Filename)
+		if file.PreviousFilename != "" {
+			files = append(files, file.PreviousFilename)
+		}
+	}
+	return files, nil
+}
@@ -0,0 +1,47 @@
+package github
+
+import (
+	"github.com/sanjP10/release/internal/tag"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+	"net/http"
+	"testing"
+)
+
+func TestChangedFiles(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	gock.New("https://api.github.com").
+		Get("/repos/repo/compare/billing/1.0.0...hash").
+		Reply(http.StatusOK).
+		JSON(Comparison{Files: []ComparedFile{{Filename: "billing/main.go"}, {Filename: "auth/new.go", PreviousFilename: "auth/old.go"}}})
+	assertTest := assert.New(t)
+	repo := Properties{Username: "user", Repo: "repo", RepoProperties: tag.RepoProperties{Password: "token", Hash: "hash"}}
+	files, err := repo.ChangedFiles("billing/1.0.0")
+	assertTest.NoError(err)
+	assertTest.Equal([]string{"billing/main.go", "auth/new.go", "auth/old.go"}, files)
+}
+
+func TestChangedFilesTooMany(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	gock.New("https://api.github.com").
+		Get("/repos/repo/compare/1.0.0...hash").
+		Reply(http.StatusOK).
+		JSON(Comparison{Files: make([]ComparedFile, compareFileLimit)})
+	repo := Properties{Username: "user", Repo: "repo", RepoProperties: tag.RepoProperties{Password: "token", Hash: "hash"}}
+	_, err := repo.ChangedFiles("1.0.0")
+	assert.ErrorContains(t, err, "at most 300 changed files")
+}
+
+func TestChangedFilesNotFound(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	gock.New("https://api.github.com").
+		Get("/repos/repo/compare/1.0.0...hash").
+		Reply(http.StatusNotFound).
+		BodyString("Not Found")
+	repo := Properties{Username: "user", Repo: "repo", RepoProperties: tag.RepoProperties{Password: "token", Hash: "hash"}}
+	_, err := repo.ChangedFiles("1.0.0")
+	assert.EqualError(t, err, "unable to compare 1.0.0 with hash, status 404: Not Found")
+}
@@ -0,0 +1,28 @@
+package github
+
+import (
+	"fmt"
+	"github.com/sanjP10/release/internal/tag"
+	"net/http"
+	urllib "net/url"
+)
+
+// ReadFile reads the raw contents of a file at the ref with the contents API
+func (r *Properties) ReadFile(ref string, path string) (string, error) {
+	url := fmt.Sprintf("%s/repos/%s/contents/%s?ref=%s", r.apiURL(), r.Repo, tag.EscapePath(path), urllib.QueryEscape(ref))
+	request, err := http.NewRequestWithContext(r.RequestContext(), "GET", url, nil)
+	if err != nil {
+		return "", err
+	}
+	request.Header.Set("Accept", "application/vnd.github.raw")
+	err = r.authorize(request)
+	if err != nil {
+		return "", err
+	}
+	resp, err := r.HTTPClient().Do(request)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	return tag.ReadFileResponse(resp)
+}
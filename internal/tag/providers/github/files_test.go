@@ -0,0 +1,32 @@
+package github
+
+import (
+	"github.com/sanjP10/release/internal/tag"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+	"net/http"
+	"testing"
+)
+
+func TestReadFile(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	gock.New("https://api.github.com").
+		Get("/repos/repo/contents/services/billing/CHANGELOG.md").
+		MatchParam("ref", "main").
+		MatchHeader("Accept", "application/vnd.github.raw").
+		Reply(http.StatusOK).
+		BodyString("## 1.0.0")
+	gock.New("https://api.github.com").
+		Get("/repos/repo/contents/CHANGELOG.md").
+		Reply(http.StatusNotFound).
+		JSON(BadResponse{Message: "Not Found"})
+	assertTest := assert.New(t)
+	repo := Properties{Username: "user", Repo: "repo", RepoProperties: tag.RepoProperties{Password: "token"}}
+	contents, err := repo.ReadFile("main", "services/billing/CHANGELOG.md")
+	assertTest.NoError(err)
+	assertTest.Equal("## 1.0.0", contents)
+
+	_, err = repo.ReadFile("main", "CHANGELOG.md")
+	assertTest.ErrorIs(err, tag.ErrFileNotFound)
+}
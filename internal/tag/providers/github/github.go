@@ -47,43 +47,70 @@ type Properties struct {
 	Username string
 	Repo     string
 	Host     string
+	// AuthType is basic (default) with Username and Password, bearer with Password as the token, or app
+	AuthType string
+	// AppID and AppKey, the app private key file, authenticate as a GitHub App when AuthType is app
+	AppID  string
+	AppKey string
+	// InstallationID of the app, looked up from Repo when empty
+	InstallationID string
+	// installationToken is exchanged for the app JWT on the first request
+	installationToken string
+}
+
+// apiURL is the REST API root of github.com or GitHub Enterprise Server
+func (r *Properties) apiURL() string {
+	if r.Host == "" {
+		return "https://api.github.com"
+	}
+	return r.Host + "/api/v3"
+}
+
+// ReleaseURL is the page of the release on github.com or GitHub Enterprise
+func (r *Properties) ReleaseURL() string {
+	host := "https://github.com"
+	if r.Host != "" {
+		host = r.Host
+	}
+	return fmt.Sprintf("%s/%s/releases/tag/%s", host, r.Repo, tag.EscapePath(r.Tag))
 }
 
 // ValidateTag checks a tag does not exist or has the same hash
 func (r *Properties) ValidateTag() tag.ValidTagState {
 	// Check tag exists, if 404 gd, 403 auth error, 200 exists and check hash is the same
 	validTag := tag.ValidTagState{TagDoesntExist: false, TagExistsWithProvidedHash: false}
-	url := ""
-	if r.Host == "" {
-		url = fmt.Sprintf("https://api.github.com/repos/%s/git/refs/tags/%s", r.Repo, r.Tag)
-	} else {
-		url = fmt.Sprintf("%s/api/v3/repos/%s/git/refs/tags/%s", r.Host, r.Repo, r.Tag)
-	}
-	request, err := http.NewRequest("GET", url, nil)
+	// slashes in the tag are part of the ref path
+	url := fmt.Sprintf("%s/repos/%s/git/refs/tags/%s", r.apiURL(), r.Repo, tag.EscapePath(r.Tag))
+	request, err := http.NewRequestWithContext(r.RequestContext(), "GET", url, nil)
 	if err != nil {
 		fmt.Println("Error validate tag request")
 	}
 	if request == nil {
-		_, err := os.Stderr.WriteString("Error creating request\n")
-		if err != nil {
+		_, errorWriting := os.Stderr.WriteString("Error creating request\n")
+		if errorWriting != nil {
 			panic("Cannot write to stderr")
 		}
-		return validTag
+		return tag.UnknownTagState(err)
+	}
+	err = r.authorize(request)
+	if err != nil {
+		fmt.Println("Error authenticating tag request", err)
+		return tag.UnknownTagState(err)
 	}
-	request.SetBasicAuth(r.Username, r.Password)
-	client := &http.Client{}
+	client := r.HTTPClient()
 
 	resp, err := client.Do(request)
 	if err != nil {
 		fmt.Println("Error validate tag request")
 	}
 	if resp == nil {
-		_, err := os.Stderr.WriteString("Error getting response\n")
-		if err != nil {
+		_, errorWriting := os.Stderr.WriteString("Error getting response\n")
+		if errorWriting != nil {
 			panic("Cannot write to stderr")
 		}
-		return validTag
+		return tag.UnknownTagState(err)
 	}
+	defer resp.Body.Close()
 
 	switch resp.StatusCode {
 	case http.StatusNotFound:
@@ -93,72 +120,97 @@ func (r *Properties) ValidateTag() tag.ValidTagState {
 		body, err := ioutil.ReadAll(resp.Body)
 		if err != nil {
 			fmt.Println("Error reading body of tag response")
+			return tag.UnknownTagState(fmt.Errorf("unable to read tag response: %w", err))
 		}
 		err = json.Unmarshal(body, &res)
 		if err != nil {
 			fmt.Println("Error unmarshalling body")
+			return tag.UnknownTagState(fmt.Errorf("unexpected tag response: %w", err))
 		}
 		if r.Hash == res.Object.Sha {
 			validTag.TagExistsWithProvidedHash = true
 		}
+	default:
+		validTag = tag.UnexpectedResponse(resp)
 	}
 	return validTag
 }
 
 // CreateTag creates a github tag
 func (r *Properties) CreateTag() bool {
+	return r.CreateWithRetry(r.ValidateTag, r.createRelease, r.handleCreateResponse, nil)
+}
+
+func (r *Properties) createRelease() (*http.Response, error) {
+	url := fmt.Sprintf("%s/repos/%s/releases", r.apiURL(), r.Repo)
+
+	body := Release{Name: r.Tag, TagName: r.Tag, Body: r.Body, Draft: false, Prerelease: false, TargetCommitish: r.Hash}
+
+	jsonBody, err := json.Marshal(body)
+	if err != nil {
+		fmt.Println("error marshalling object:", err)
+	}
+	request, err := http.NewRequestWithContext(r.RequestContext(), "POST", url, bytes.NewBuffer(jsonBody))
+	if err != nil {
+		fmt.Println("Error creating tag request", err)
+		return nil, err
+	}
+	request.Header.Add("Content-Type", "application/json")
+	err = r.authorize(request)
+	if err != nil {
+		fmt.Println("Error authenticating tag request", err)
+		return nil, err
+	}
+	client := r.HTTPClient()
+	return client.Do(request)
+}
+
+func (r *Properties) handleCreateResponse(resp *http.Response, err error) bool {
 	createTag := false
-	validTagState := r.ValidateTag()
-	if validTagState.TagExistsWithProvidedHash {
-		createTag = true
-	} else if validTagState.TagDoesntExist {
-		url := ""
-		if r.Host == "" {
-			url = fmt.Sprintf("https://api.github.com/repos/%s/releases", r.Repo)
-		} else {
-			url = fmt.Sprintf("%s/api/v3/repos/%s/releases", r.Host, r.Repo)
+	if err != nil {
+		fmt.Println("Error creating tag", err)
+	}
+	if resp == nil {
+		_, errorWriting := os.Stderr.WriteString("Error getting response\n")
+		if errorWriting != nil {
+			panic("Cannot write to stderr")
 		}
+		return r.FailRequest(err)
+	}
+	defer resp.Body.Close()
 
-		body := Release{Name: r.Tag, TagName: r.Tag, Body: r.Body, Draft: false, Prerelease: false, TargetCommitish: r.Hash}
-
-		jsonBody, err := json.Marshal(body)
+	switch resp.StatusCode {
+	case http.StatusNotFound:
+		_, err := os.Stderr.WriteString("Repo not found\n")
 		if err != nil {
-			fmt.Println("error marshalling object:", err)
+			panic("Cannot write to stderr")
 		}
-		request, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonBody))
+		r.Fail(resp.StatusCode, "repo not found")
+	case http.StatusCreated:
+		createTag = true
+	case http.StatusUnprocessableEntity:
+		res := BadResponse{}
+		body, err := ioutil.ReadAll(resp.Body)
 		if err != nil {
-			fmt.Println("Error creating tag request", err)
+			fmt.Println("Error reading body of error response")
 		}
-		request.Header.Add("Content-Type", "application/json")
-		request.SetBasicAuth(r.Username, r.Password)
-		client := &http.Client{}
-		resp, err := client.Do(request)
+		err = json.Unmarshal(body, &res)
 		if err != nil {
-			fmt.Println("Error creating tag", err)
+			fmt.Println("Error unmarshalling response")
 		}
-
-		switch resp.StatusCode {
-		case http.StatusNotFound:
-			_, err := os.Stderr.WriteString("Repo not found\n")
-			if err != nil {
-				panic("Cannot write to stderr")
-			}
-		case http.StatusCreated:
-			createTag = true
-		case http.StatusUnprocessableEntity:
-			res := BadResponse{}
-			body, err := ioutil.ReadAll(resp.Body)
-			if err != nil {
-				fmt.Println("Error reading body of error response")
-			}
-			err = json.Unmarshal(body, &res)
-			if err != nil {
-				fmt.Println("Error unmarshalling response")
-			}
-			_, errorWriting := os.Stderr.WriteString(res.Errors[0].Code)
-			if errorWriting != nil {
-				panic("Cannot write to stderr")
-			}
+		message := res.Message
+		if len(res.Errors) > 0 {
+			message = res.Errors[0].Code
+		}
+		_, errorWriting := os.Stderr.WriteString(message + "\n")
+		if errorWriting != nil {
+			panic("Cannot write to stderr")
+		}
+		r.Fail(resp.StatusCode, message)
+	default:
+		_, errorWriting := os.Stderr.WriteString("Error creating tag, " + r.FailResponse(resp).Error() + "\n")
+		if errorWriting != nil {
+			panic("Cannot write to stderr")
 		}
 	}
 	return createTag
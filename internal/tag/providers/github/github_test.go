@@ -2,10 +2,13 @@ package github
 
 import (
 	"github.com/sanjP10/release/internal/tag"
+	"github.com/sanjP10/release/internal/tag/faulttest"
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/h2non/gock.v1"
 	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 func TestValidateTagNotExisting(t *testing.T) {
@@ -71,6 +74,24 @@ func TestValidateTagOtherError(t *testing.T) {
 	assertTest.False(results.TagExistsWithProvidedHash)
 }
 
+func TestValidateTagForbidden(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	gock.New("https://api.github.com").
+		Get("/repos/repo/git/refs/tags/tag").
+		Reply(http.StatusForbidden).
+		JSON(BadResponse{Message: "Resource not accessible by integration"})
+	assertTest := assert.New(t)
+	// Testing a 403 is reported as unknown rather than an existing tag
+	repo := Properties{Username: "username", Repo: "repo", Host: "", RepoProperties: tag.RepoProperties{Password: "password", Tag: "tag", Hash: "hash"}}
+	results := repo.ValidateTag()
+	assertTest.False(results.TagDoesntExist)
+	assertTest.False(results.TagExistsWithProvidedHash)
+	assertTest.True(results.Unknown)
+	assertTest.Equal(http.StatusForbidden, results.StatusCode)
+	assertTest.Contains(results.Message, "Resource not accessible by integration")
+}
+
 func TestCreateTagNotFound(t *testing.T) {
 	// Testing tag not existing
 	target := Object{Sha: "tag"}
@@ -147,7 +168,7 @@ func TestCreateError(t *testing.T) {
 	errorResponse := BadResponse{Errors: []Error{errorMessage}}
 	defer gock.Off() // Flush pending mocks after test execution
 	gock.New("https://api.github.com").
-		Get("/repos/repo/git/refs/tags/tag").
+		Get("/repos/repo/git/refs/tags/test").
 		Reply(http.StatusNotFound).
 		JSON(response)
 	gock.New("https://api.github.com").
@@ -155,8 +176,9 @@ func TestCreateError(t *testing.T) {
 		Reply(http.StatusUnprocessableEntity).
 		JSON(errorResponse)
 	assertTest := assert.New(t)
-	repo := Properties{Username: "username", Repo: "repo", Host: "", RepoProperties: tag.RepoProperties{"password", "test", "hash", "hello"}}
+	repo := Properties{Username: "username", Repo: "repo", Host: "", RepoProperties: tag.RepoProperties{Password: "password", Tag: "test", Hash: "hash", Body: "hello"}}
 	assertTest.False(repo.CreateTag())
+	assertTest.Equal(tag.CreateFailure{StatusCode: http.StatusUnprocessableEntity, Message: "already_exists"}, repo.Failure())
 }
 
 func TestCreateTagOtherError(t *testing.T) {
@@ -189,3 +211,77 @@ func TestCreateTagOtherErrorResponse(t *testing.T) {
 	repo := Properties{Username: "username", Repo: "repo", Host: "", RepoProperties: tag.RepoProperties{Password: "password", Tag: "tag", Hash: "hash", Body: "hello"}}
 	assertTest.False(repo.CreateTag())
 }
+
+func TestCreateTagRetriesAfterServerError(t *testing.T) {
+	body := Release{TargetCommitish: "hash", Prerelease: false, Draft: false, Body: "hello", TagName: "tag", Name: "tag"}
+	defer gock.Off() // Flush pending mocks after test execution
+
+	gock.New("https://api.github.com").
+		Get("/repos/repo/git/refs/tags/tag").
+		Times(2).
+		Reply(http.StatusNotFound)
+	gock.New("https://api.github.com").
+		Post("/repos/repo/releases").
+		Reply(http.StatusBadGateway)
+	gock.New("https://api.github.com").
+		Post("/repos/repo/releases").
+		Reply(http.StatusCreated).
+		JSON(body)
+	assertTest := assert.New(t)
+	repo := Properties{Username: "username", Repo: "repo", Host: "", RepoProperties: tag.RepoProperties{Password: "password", Tag: "tag", Hash: "hash", Body: "hello",
+		Retry: tag.RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond}}}
+	assertTest.True(repo.CreateTag())
+	assertTest.True(gock.IsDone())
+}
+
+func TestCreateTagRetryFindsCreatedTag(t *testing.T) {
+	target := Object{Sha: "hash"}
+	response := Tag{Object: target}
+	defer gock.Off() // Flush pending mocks after test execution
+
+	gock.New("https://api.github.com").
+		Get("/repos/repo/git/refs/tags/tag").
+		Reply(http.StatusNotFound)
+	gock.New("https://api.github.com").
+		Post("/repos/repo/releases").
+		Reply(http.StatusBadGateway)
+	// the failed request was applied, so validating again finds the tag and no second release is created
+	gock.New("https://api.github.com").
+		Get("/repos/repo/git/refs/tags/tag").
+		Reply(http.StatusOK).
+		JSON(response)
+	assertTest := assert.New(t)
+	repo := Properties{Username: "username", Repo: "repo", Host: "", RepoProperties: tag.RepoProperties{Password: "password", Tag: "tag", Hash: "hash", Body: "hello",
+		Retry: tag.RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond}}}
+	assertTest.True(repo.CreateTag())
+	assertTest.True(gock.IsDone())
+}
+
+func TestProviderFaults(t *testing.T) {
+	faulttest.Run(t, func(host string, repoProperties tag.RepoProperties) faulttest.Provider {
+		return &Properties{Username: "username", Repo: "repo", Host: host, RepoProperties: repoProperties}
+	})
+}
+
+func TestReleaseURL(t *testing.T) {
+	assertTest := assert.New(t)
+	properties := Properties{Repo: "owner/repo", RepoProperties: tag.RepoProperties{Tag: "billing/1.0.0"}}
+	assertTest.Equal("https://github.com/owner/repo/releases/tag/billing/1.0.0", properties.ReleaseURL())
+	properties.Host = "https://github.example.com"
+	assertTest.Equal("https://github.example.com/owner/repo/releases/tag/billing/1.0.0", properties.ReleaseURL())
+}
+
+func TestValidateTagWithSlash(t *testing.T) {
+	assertTest := assert.New(t)
+	path := ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.EscapedPath()
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"object": {"sha": "hash"}}`))
+	}))
+	defer server.Close()
+	repo := Properties{Username: "username", Repo: "owner/repo", Host: server.URL, RepoProperties: tag.RepoProperties{Password: "password", Tag: "billing/1.3.0", Hash: "hash"}}
+	results := repo.ValidateTag()
+	assertTest.True(results.TagExistsWithProvidedHash)
+	assertTest.Equal("/api/v3/repos/owner/repo/git/refs/tags/billing/1.3.0", path)
+}
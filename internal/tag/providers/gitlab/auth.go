@@ -0,0 +1,84 @@
+package gitlab
+
+import (
+	"net/http"
+	"os"
+	"strings"
+)
+
+// Authentication types for the GitLab API
+const (
+	AuthPrivateToken = "private-token"
+	AuthJobToken     = "job-token"
+	AuthBearer       = "bearer"
+)
+
+// JobTokenEnv is the variable GitLab CI provides the job token in
+const JobTokenEnv = "CI_JOB_TOKEN"
+
+// ValidAuthType checks the authentication type from the cli is supported
+func ValidAuthType(authType string) bool {
+	switch strings.ToLower(authType) {
+	case "", AuthPrivateToken, AuthJobToken, AuthBearer:
+		return true
+	}
+	return false
+}
+
+// JobTokenAvailable reports whether a CI job token can be used when no password is provided
+func JobTokenAvailable() bool {
+	return os.Getenv(JobTokenEnv) != ""
+}
+
+// authType is the authentication type provided, otherwise the CI job token when no password is provided inside GitLab CI
+func (r *Properties) authType() string {
+	authType := strings.ToLower(r.AuthType)
+	if authType != "" {
+		return authType
+	}
+	if r.Password == "" && JobTokenAvailable() {
+		return AuthJobToken
+	}
+	if jobToken := os.Getenv(JobTokenEnv); jobToken != "" && r.Password == jobToken {
+		return AuthJobToken
+	}
+	return AuthPrivateToken
+}
+
+// authorize sets the token header of the authentication type on the request
+func (r *Properties) authorize(request *http.Request) {
+	switch r.authType() {
+	case AuthJobToken:
+		token := r.Password
+		if token == "" {
+			token = os.Getenv(JobTokenEnv)
+		}
+		request.Header.Set("JOB-TOKEN", token)
+	case AuthBearer:
+		request.Header.Set("Authorization", "Bearer "+r.Password)
+	default:
+		request.Header.Set("PRIVATE-TOKEN", r.Password)
+	}
+}
+
+// authError explains a 401 or 403 from the tags or releases API with the scopes or permissions the token is missing
+func (r *Properties) authError(statusCode int, api string, write bool) string {
+	if statusCode == http.StatusUnauthorized {
+		switch r.authType() {
+		case AuthJobToken:
+			return "Unauthorised, the CI job token was rejected, check " + JobTokenEnv + " belongs to a running job\n"
+		case AuthBearer:
+			return "Unauthorised, the OAuth token was rejected or has expired, please check credentials\n"
+		}
+		return "Unauthorised, please check credentials\n"
+	}
+	switch {
+	case r.authType() == AuthJobToken && write:
+		return "Forbidden, CI job tokens cannot use the " + api + " API to create, use a project or personal access token with the api scope\n"
+	case r.authType() == AuthJobToken:
+		return "Forbidden, the CI job token cannot read the " + api + " API of " + r.Repo + ", add this project to its CI/CD job token allowlist\n"
+	case write:
+		return "Forbidden, creating with the " + api + " API needs a token with the api scope and at least the Developer role, Maintainer for protected tags\n"
+	}
+	return "Forbidden, reading the " + api + " API needs a token with the read_api scope and at least the Reporter role\n"
+}
@@ -0,0 +1,72 @@
+package gitlab
+
+import (
+	"github.com/sanjP10/release/internal/tag"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+	"net/http"
+	"testing"
+)
+
+func TestValidateTagJobTokenDetected(t *testing.T) {
+	t.Setenv(JobTokenEnv, "job-token")
+	defer gock.Off() // Flush pending mocks after test execution
+
+	gock.New("https://gitlab.com/").
+		Get("/api/v4/projects/repo/repository/tags/tag").
+		MatchHeader("JOB-TOKEN", "^job-token$").
+		Reply(http.StatusNotFound)
+	repo := Properties{Repo: "repo", RepoProperties: tag.RepoProperties{Tag: "tag", Hash: "hash"}}
+	assert.True(t, repo.ValidateTag().TagDoesntExist)
+}
+
+func TestValidateTagPrivateTokenPreferred(t *testing.T) {
+	t.Setenv(JobTokenEnv, "job-token")
+	defer gock.Off() // Flush pending mocks after test execution
+
+	gock.New("https://gitlab.com/").
+		Get("/api/v4/projects/repo/repository/tags/tag").
+		MatchHeader("PRIVATE-TOKEN", "^password$").
+		Reply(http.StatusNotFound)
+	repo := Properties{Repo: "repo", RepoProperties: tag.RepoProperties{Password: "password", Tag: "tag", Hash: "hash"}}
+	assert.True(t, repo.ValidateTag().TagDoesntExist)
+}
+
+func TestValidateTagBearer(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	gock.New("https://gitlab.com/").
+		Get("/api/v4/projects/repo/repository/tags/tag").
+		MatchHeader("Authorization", "^Bearer token$").
+		Reply(http.StatusNotFound)
+	repo := Properties{Repo: "repo", AuthType: AuthBearer, RepoProperties: tag.RepoProperties{Password: "token", Tag: "tag", Hash: "hash"}}
+	assert.True(t, repo.ValidateTag().TagDoesntExist)
+}
+
+func TestValidateTagForbidden(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	gock.New("https://gitlab.com/").
+		Get("/api/v4/projects/repo/repository/tags/tag").
+		Reply(http.StatusForbidden).
+		JSON(BadResponse{Message: "403 Forbidden"})
+	assertTest := assert.New(t)
+	repo := Properties{Repo: "repo", RepoProperties: tag.RepoProperties{Password: "password", Tag: "tag", Hash: "hash"}}
+	results := repo.ValidateTag()
+	assertTest.False(results.TagDoesntExist)
+	assertTest.True(results.Unknown)
+	assertTest.Equal(http.StatusForbidden, results.StatusCode)
+}
+
+func TestAuthError(t *testing.T) {
+	assertTest := assert.New(t)
+	repo := Properties{Repo: "group/repo", AuthType: AuthJobToken}
+	assertTest.Contains(repo.authError(http.StatusForbidden, "tags", true), "project or personal access token with the api scope")
+	assertTest.Contains(repo.authError(http.StatusForbidden, "tags", false), "job token allowlist")
+	assertTest.Contains(repo.authError(http.StatusUnauthorized, "tags", false), JobTokenEnv)
+
+	repo.AuthType = AuthPrivateToken
+	assertTest.Contains(repo.authError(http.StatusForbidden, "releases", true), "api scope")
+	assertTest.Contains(repo.authError(http.StatusForbidden, "tags", false), "read_api scope")
+	assertTest.Equal("Unauthorised, please check credentials\n", repo.authError(http.StatusUnauthorized, "tags", false))
+}
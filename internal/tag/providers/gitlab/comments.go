@@ -0,0 +1,75 @@
+package gitlab
+
+import (
+	"errors"
+	"fmt"
+	"github.com/sanjP10/release/internal/tag"
+	"net/http"
+	"strings"
+)
+
+// notesPageSize is the number of merge request notes requested per page
+const notesPageSize = 100
+
+// Note is a comment on a merge request
+type Note struct {
+	ID   int64  `json:"id,omitempty"`
+	Body string `json:"body"`
+}
+
+// UpsertComment updates the note on the merge request starting with the marker, or adds a new note
+func (r *Properties) UpsertComment(pullRequest int, marker string, body string) error {
+	notesURL := fmt.Sprintf("%s/merge_requests/%d/notes", r.projectURL(), pullRequest)
+	id, err := r.findNote(notesURL, marker)
+	if err != nil {
+		return err
+	}
+	method, url := "POST", notesURL
+	if id != 0 {
+		method, url = "PUT", fmt.Sprintf("%s/%d", notesURL, id)
+	}
+	request, err := tag.NewJSONRequest(r.RequestContext(), method, url, Note{Body: body})
+	if err != nil {
+		return err
+	}
+	r.authorize(request)
+	resp, err := r.HTTPClient().Do(request)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return errors.New(strings.TrimSpace(r.authError(resp.StatusCode, "notes", true)))
+	}
+	return tag.CheckWriteResponse(resp)
+}
+
+// findNote pages through the notes of the merge request for the one starting with the marker, 0 when there is none
+func (r *Properties) findNote(notesURL string, marker string) (int64, error) {
+	for page := 1; ; page++ {
+		url := fmt.Sprintf("%s?per_page=%d&page=%d", notesURL, notesPageSize, page)
+		request, err := http.NewRequestWithContext(r.RequestContext(), "GET", url, nil)
+		if err != nil {
+			return 0, err
+		}
+		r.authorize(request)
+		resp, err := r.HTTPClient().Do(request)
+		if err != nil {
+			return 0, err
+		}
+		var notes []Note
+		err = tag.DecodeJSON(resp, &notes)
+		resp.Body.Close()
+		if err != nil {
+			return 0, fmt.Errorf("unable to list notes of merge request, %w", err)
+		}
+		for _, note := range notes {
+			if strings.HasPrefix(note.Body, marker) {
+				return note.ID, nil
+			}
+		}
+		if len(notes) < notesPageSize {
+			return 0, nil
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package gitlab
+
+import (
+	"github.com/sanjP10/release/internal/tag"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+	"net/http"
+	"testing"
+)
+
+func TestUpsertCommentUpdatesNote(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	gock.New("https://gitlab.com/").
+		Get("api/v4/projects/org/repo/merge_requests/5/notes").
+		Reply(http.StatusOK).
+		JSON([]Note{{ID: 8, Body: "> <!-- marker -->\nquoted"}, {ID: 9, Body: "<!-- marker -->\nold"}})
+	gock.New("https://gitlab.com/").
+		Put("api/v4/projects/org/repo/merge_requests/5/notes/9").
+		JSON(Note{Body: "<!-- marker -->\nnew"}).
+		Reply(http.StatusOK).
+		JSON(Note{ID: 9})
+	repo := Properties{Repo: "org/repo", RepoProperties: tag.RepoProperties{Password: "token"}}
+	assert.NoError(t, repo.UpsertComment(5, "<!-- marker -->", "<!-- marker -->\nnew"))
+	assert.True(t, gock.IsDone())
+}
+
+func TestUpsertCommentJobTokenForbidden(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	gock.New("https://gitlab.com/").
+		Get("api/v4/projects/org/repo/merge_requests/5/notes").
+		Reply(http.StatusOK).
+		JSON([]Note{})
+	gock.New("https://gitlab.com/").
+		Post("api/v4/projects/org/repo/merge_requests/5/notes").
+		Reply(http.StatusForbidden)
+	repo := Properties{Repo: "org/repo", AuthType: AuthJobToken, RepoProperties: tag.RepoProperties{Password: "job"}}
+	err := repo.UpsertComment(5, "<!-- marker -->", "body")
+	assert.EqualError(t, err, "Forbidden, CI job tokens cannot use the notes API to create, use a project or personal access token with the api scope")
+}
@@ -0,0 +1,48 @@
+package gitlab
+
+import (
+	"fmt"
+	"github.com/sanjP10/release/internal/tag"
+	"net/http"
+	urllib "net/url"
+)
+
+// Diff is a file changed between two commits
+type Diff struct {
+	OldPath string `json:"old_path"`
+	NewPath string `json:"new_path"`
+}
+
+// Comparison response of the compare API
+type Comparison struct {
+	Diffs []Diff `json:"diffs"`
+}
+
+// ChangedFiles lists the files changed between the base tag and the hash with the compare API
+func (r *Properties) ChangedFiles(base string) ([]string, error) {
+	url := fmt.Sprintf("%s/repository/compare?from=%s&to=%s&straight=true",
+		r.projectURL(), urllib.QueryEscape(base), urllib.QueryEscape(r.Hash))
+	request, err := http.NewRequestWithContext(r.RequestContext(), "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	r.authorize(request)
+	resp, err := r.HTTPClient().Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	comparison := Comparison{}
+	err = tag.DecodeJSON(resp, &comparison)
+	if err != nil {
+		return nil, fmt.Errorf("unable to compare %s with %s, %w", base, r.Hash, err)
+	}
+	var files []string
+	for _, diff := range comparison.Diffs {
+		files = append(files, diff.NewPath)
+		if diff.OldPath != diff.NewPath {
+			files = append(files, diff.OldPath)
+		}
+	}
+	return files, nil
+}
@@ -0,0 +1,38 @@
+package gitlab
+
+import (
+	"github.com/sanjP10/release/internal/tag"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+	"net/http"
+	"testing"
+)
+
+func TestChangedFiles(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	gock.New("https://gitlab.com/").
+		Get("api/v4/projects/org/repo/repository/compare").
+		MatchParam("from", "billing/1.0.0").
+		MatchParam("to", "hash").
+		MatchHeader("PRIVATE-TOKEN", "token").
+		Reply(http.StatusOK).
+		JSON(Comparison{Diffs: []Diff{{OldPath: "billing/main.go", NewPath: "billing/main.go"}, {OldPath: "auth/old.go", NewPath: "auth/new.go"}}})
+	assertTest := assert.New(t)
+	repo := Properties{Repo: "org/repo", RepoProperties: tag.RepoProperties{Password: "token", Hash: "hash"}}
+	files, err := repo.ChangedFiles("billing/1.0.0")
+	assertTest.NoError(err)
+	assertTest.Equal([]string{"billing/main.go", "auth/new.go", "auth/old.go"}, files)
+}
+
+func TestChangedFilesForbidden(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	gock.New("https://gitlab.example.com/").
+		Get("api/v4/projects/org/repo/repository/compare").
+		Reply(http.StatusForbidden).
+		JSON(BadResponse{Message: "403 Forbidden"})
+	repo := Properties{Repo: "org/repo", Host: "https://gitlab.example.com", RepoProperties: tag.RepoProperties{Password: "token", Hash: "hash"}}
+	_, err := repo.ChangedFiles("1.0.0")
+	assert.ErrorContains(t, err, "unable to compare 1.0.0 with hash, status 403")
+}
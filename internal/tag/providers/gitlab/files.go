@@ -0,0 +1,24 @@
+package gitlab
+
+import (
+	"fmt"
+	"github.com/sanjP10/release/internal/tag"
+	"net/http"
+	urllib "net/url"
+)
+
+// ReadFile reads the raw contents of a file at the ref with the repository files API
+func (r *Properties) ReadFile(ref string, path string) (string, error) {
+	url := fmt.Sprintf("%s/repository/files/%s/raw?ref=%s", r.projectURL(), urllib.PathEscape(path), urllib.QueryEscape(ref))
+	request, err := http.NewRequestWithContext(r.RequestContext(), "GET", url, nil)
+	if err != nil {
+		return "", err
+	}
+	r.authorize(request)
+	resp, err := r.HTTPClient().Do(request)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	return tag.ReadFileResponse(resp)
+}
@@ -0,0 +1,37 @@
+package gitlab
+
+import (
+	"github.com/sanjP10/release/internal/tag"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+	"net/http"
+	"testing"
+)
+
+func TestReadFile(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	gock.New("https://gitlab.com/").
+		Get("api/v4/projects/org/repo/repository/files/services/billing/CHANGELOG.md/raw").
+		MatchParam("ref", "main").
+		MatchHeader("PRIVATE-TOKEN", "token").
+		Reply(http.StatusOK).
+		BodyString("## 1.0.0")
+	assertTest := assert.New(t)
+	repo := Properties{Repo: "org/repo", RepoProperties: tag.RepoProperties{Password: "token"}}
+	contents, err := repo.ReadFile("main", "services/billing/CHANGELOG.md")
+	assertTest.NoError(err)
+	assertTest.Equal("## 1.0.0", contents)
+}
+
+func TestReadFileUnauthorized(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	gock.New("https://gitlab.com/").
+		Get("api/v4/projects/org/repo/repository/files/CHANGELOG.md/raw").
+		Reply(http.StatusUnauthorized).
+		JSON(BadResponse{Message: "401 Unauthorized"})
+	repo := Properties{Repo: "org/repo", RepoProperties: tag.RepoProperties{Password: "token"}}
+	_, err := repo.ReadFile("main", "CHANGELOG.md")
+	assert.ErrorContains(t, err, "status 401")
+}
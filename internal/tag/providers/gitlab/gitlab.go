@@ -9,6 +9,8 @@ import (
 	"net/http"
 	urllib "net/url"
 	"os"
+	"strings"
+	"time"
 )
 
 // Commit Structure of gitlab tag target
@@ -36,170 +38,239 @@ type Properties struct {
 	tag.RepoProperties
 	Repo string
 	Host string
+	// AuthType is private-token, job-token or bearer, detected from CI_JOB_TOKEN when empty
+	AuthType string
+}
+
+// projectURL is the API root of the project on gitlab.com or a self-hosted instance
+func (r *Properties) projectURL() string {
+	host := "https://gitlab.com"
+	if r.Host != "" {
+		host = r.Host
+	}
+	return fmt.Sprintf("%s/api/v4/projects/%s", host, urllib.QueryEscape(r.Repo))
+}
+
+// ReleaseURL is the page of the release on gitlab.com or a self-hosted instance
+func (r *Properties) ReleaseURL() string {
+	host := "https://gitlab.com"
+	if r.Host != "" {
+		host = r.Host
+	}
+	return fmt.Sprintf("%s/%s/-/releases/%s", host, r.Repo, urllib.PathEscape(r.Tag))
 }
 
 // ValidateTag checks a tag does not exist or has the same hash
 func (r *Properties) ValidateTag() tag.ValidTagState {
 	// Check tag exists, if 404 gd, 403 auth error, 200 exists and check hash is the same
 	validTag := tag.ValidTagState{TagDoesntExist: false, TagExistsWithProvidedHash: false}
-	url := ""
-	if r.Host == "" {
-		url = fmt.Sprintf("https://gitlab.com/api/v4/projects/%s/repository/tags/%s", urllib.QueryEscape(r.Repo), r.Tag)
-	} else {
-		url = fmt.Sprintf("%s/api/v4/projects/%s/repository/tags/%s", r.Host, urllib.QueryEscape(r.Repo), r.Tag)
-	}
-	request, err := http.NewRequest("GET", url, nil)
+	url := fmt.Sprintf("%s/repository/tags/%s", r.projectURL(), urllib.PathEscape(r.Tag))
+	request, err := http.NewRequestWithContext(r.RequestContext(), "GET", url, nil)
 	if err != nil {
 		fmt.Println("Error validate tag request")
 	}
 	if request == nil {
-		_, err := os.Stderr.WriteString("Error creating request\n")
-		if err != nil {
+		_, errorWriting := os.Stderr.WriteString("Error creating request\n")
+		if errorWriting != nil {
 			panic("Cannot write to stderr")
 		}
-		return validTag
+		return tag.UnknownTagState(err)
 	}
-	request.Header.Set("PRIVATE-TOKEN", r.Password)
-	client := &http.Client{}
+	r.authorize(request)
+	client := r.HTTPClient()
 	resp, err := client.Do(request)
 	if err != nil {
 		fmt.Println("Error validate tag request")
 	}
 	if resp == nil {
-		_, err := os.Stderr.WriteString("Error getting response\n")
-		if err != nil {
+		_, errorWriting := os.Stderr.WriteString("Error getting response\n")
+		if errorWriting != nil {
 			panic("Cannot write to stderr")
 		}
-		return validTag
+		return tag.UnknownTagState(err)
 	}
+	defer resp.Body.Close()
 	switch resp.StatusCode {
 	case http.StatusNotFound:
 		validTag.TagDoesntExist = true
-	case http.StatusUnauthorized:
-		_, err := os.Stderr.WriteString("Unauthorised, please check credentials\n")
+	case http.StatusUnauthorized, http.StatusForbidden:
+		_, err := os.Stderr.WriteString(r.authError(resp.StatusCode, "tags", false))
 		if err != nil {
 			panic("Cannot write to stderr")
 		}
+		validTag = tag.UnexpectedResponse(resp)
 	case http.StatusOK:
 		res := Tag{}
 		body, err := ioutil.ReadAll(resp.Body)
 		if err != nil {
 			fmt.Println("Error reading body of tag response")
+			return tag.UnknownTagState(fmt.Errorf("unable to read tag response: %w", err))
 		}
 		err = json.Unmarshal(body, &res)
 		if err != nil {
 			fmt.Println("Error unmarshalling body")
+			return tag.UnknownTagState(fmt.Errorf("unexpected tag response: %w", err))
 		}
 		if r.Hash == res.Commit.ID {
 			validTag.TagExistsWithProvidedHash = true
 		}
+	default:
+		validTag = tag.UnexpectedResponse(resp)
 	}
 	return validTag
 }
 
 // CreateTag creates a Gitlab tag
 func (r *Properties) CreateTag() bool {
+	// a tag request applied before failing still needs its release, which may have been created by then
+	return r.CreateWithRetry(r.ValidateTag, r.createTagRequest, r.handleCreateResponse, func() bool { return r.createRelease(true) })
+}
+
+func (r *Properties) createTagRequest() (*http.Response, error) {
+	url := fmt.Sprintf("%s/repository/tags", r.projectURL())
+	request, err := http.NewRequestWithContext(r.RequestContext(), "POST", url, nil)
+	if err != nil {
+		fmt.Println("Error creating tag request", err)
+		return nil, err
+	}
+	q := request.URL.Query()
+	q.Add("tag_name", r.Tag)
+	q.Add("ref", r.Hash)
+	request.URL.RawQuery = q.Encode()
+	request.Header.Add("Content-Type", "application/json")
+	r.authorize(request)
+	client := r.HTTPClient()
+	return client.Do(request)
+}
+
+func (r *Properties) handleCreateResponse(resp *http.Response, err error) bool {
 	createTag := false
-	validTagState := r.ValidateTag()
-	if validTagState.TagExistsWithProvidedHash {
-		createTag = true
-	} else if validTagState.TagDoesntExist {
-		url := ""
-		if r.Host == "" {
-			url = fmt.Sprintf("https://gitlab.com/api/v4/projects/%s/repository/tags", urllib.QueryEscape(r.Repo))
-		} else {
-			url = fmt.Sprintf("%s/api/v4/projects/%s/repository/tags", r.Host, urllib.QueryEscape(r.Repo))
-		}
-
-		request, err := http.NewRequest("POST", url, nil)
+	if err != nil {
+		fmt.Println("Error creating tag", err)
+	}
+	if resp == nil {
+		_, errorWriting := os.Stderr.WriteString("Error getting response\n")
+		if errorWriting != nil {
+			panic("Cannot write to stderr")
+		}
+		return r.FailRequest(err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		message := r.authError(resp.StatusCode, "tags", true)
+		_, err := os.Stderr.WriteString(message)
 		if err != nil {
-			fmt.Println("Error creating tag request", err)
-		}
-		q := request.URL.Query()
-		q.Add("tag_name", r.Tag)
-		q.Add("ref", r.Hash)
-		request.URL.RawQuery = q.Encode()
-		request.Header.Add("Content-Type", "application/json")
-		request.Header.Add("PRIVATE-TOKEN", r.Password)
-		client := &http.Client{}
-		resp, err := client.Do(request)
+			panic("Cannot write to stderr")
+		}
+		r.Fail(resp.StatusCode, strings.TrimSpace(message))
+	case http.StatusNotFound:
+		_, err := os.Stderr.WriteString("Repo not found\n")
 		if err != nil {
-			fmt.Println("Error creating tag", err)
+			panic("Cannot write to stderr")
 		}
-
-		switch resp.StatusCode {
-		case http.StatusUnauthorized:
-			_, err := os.Stderr.WriteString("Unauthorised, please check credentials\n")
-			if err != nil {
-				panic("Cannot write to stderr")
-			}
-		case http.StatusNotFound:
-			_, err := os.Stderr.WriteString("Repo not found\n")
-			if err != nil {
-				panic("Cannot write to stderr")
-			}
-		case http.StatusCreated:
-			// Create release notes with tag
-			createTag = r.createRelease()
-		case http.StatusBadRequest:
-			res := BadResponse{}
-			body, err := ioutil.ReadAll(resp.Body)
-			if err != nil {
-				fmt.Println("Error reading body of error response")
-			}
-			err = json.Unmarshal(body, &res)
-			if err != nil {
-				fmt.Println("Error unmarshalling response")
-			}
-			_, errorWriting := os.Stderr.WriteString(res.Message)
-			if errorWriting != nil {
-				panic("Cannot write to stderr")
-			}
+		r.Fail(resp.StatusCode, "repo not found")
+	case http.StatusCreated:
+		// Create release notes with tag
+		createTag = r.createRelease(false)
+	case http.StatusBadRequest:
+		res := BadResponse{}
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			fmt.Println("Error reading body of error response")
+		}
+		err = json.Unmarshal(body, &res)
+		if err != nil {
+			fmt.Println("Error unmarshalling response")
+		}
+		_, errorWriting := os.Stderr.WriteString(res.Message + "\n")
+		if errorWriting != nil {
+			panic("Cannot write to stderr")
+		}
+		r.Fail(resp.StatusCode, res.Message)
+	default:
+		_, errorWriting := os.Stderr.WriteString("Error creating tag, " + r.FailResponse(resp).Error() + "\n")
+		if errorWriting != nil {
+			panic("Cannot write to stderr")
 		}
 	}
 	return createTag
 }
 
-func (r *Properties) createRelease() bool {
-	createdRelease := false
-	release := ""
-	if r.Host == "" {
-		release = fmt.Sprintf("https://gitlab.com/api/v4/projects/%s/repository/tags/%s/release", urllib.QueryEscape(r.Repo), r.Tag)
-	} else {
-		release = fmt.Sprintf("%s/api/v4/projects/%s/repository/tags/%s/release", r.Host, urllib.QueryEscape(r.Repo), r.Tag)
+// createRelease adds the release notes to the tag, a conflict means the release exists when the tag was created by an
+// earlier request
+func (r *Properties) createRelease(existingTag bool) bool {
+	for attempt := 0; ; attempt++ {
+		resp, err := r.createReleaseRequest()
+		delay, retry := r.Retry.Retryable(resp, err, attempt)
+		if !retry {
+			// a conflict after retrying means an earlier attempt was applied before failing
+			if (attempt > 0 || existingTag) && err == nil && resp != nil && resp.StatusCode == http.StatusConflict {
+				resp.Body.Close()
+				return true
+			}
+			return r.handleReleaseResponse(resp, err)
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+		_, errorWriting := os.Stderr.WriteString("Retrying release creation in " + delay.Round(time.Second).String() + "\n")
+		if errorWriting != nil {
+			panic("Cannot write to stderr")
+		}
+		if err := tag.Wait(r.RequestContext(), delay); err != nil {
+			return r.FailRequest(err)
+		}
 	}
+}
+
+func (r *Properties) createReleaseRequest() (*http.Response, error) {
+	release := fmt.Sprintf("%s/repository/tags/%s/release", r.projectURL(), urllib.PathEscape(r.Tag))
 	body := Release{r.Body}
 	jsonBody, err := json.Marshal(body)
 	if err != nil {
 		fmt.Println("error marshalling object:", err)
 	}
-	request, _ := http.NewRequest("POST", release, bytes.NewBuffer(jsonBody))
-	if request == nil {
-		_, err := os.Stderr.WriteString("Error creating request\n")
-		if err != nil {
-			panic("Cannot write to stderr")
-		}
-		return false
+	request, err := http.NewRequestWithContext(r.RequestContext(), "POST", release, bytes.NewBuffer(jsonBody))
+	if err != nil {
+		fmt.Println("Error creating release request", err)
+		return nil, err
 	}
 	request.Header.Add("Content-Type", "application/json")
-	request.Header.Add("PRIVATE-TOKEN", r.Password)
-	client := &http.Client{}
-	resp, err := client.Do(request)
+	r.authorize(request)
+	client := r.HTTPClient()
+	return client.Do(request)
+}
+
+func (r *Properties) handleReleaseResponse(resp *http.Response, err error) bool {
+	createdRelease := false
 	if err != nil {
 		fmt.Println("Error creating tag", err)
 	}
+	if resp == nil {
+		_, errorWriting := os.Stderr.WriteString("Error getting response\n")
+		if errorWriting != nil {
+			panic("Cannot write to stderr")
+		}
+		return r.FailRequest(err)
+	}
+	defer resp.Body.Close()
 
 	switch resp.StatusCode {
-	case http.StatusUnauthorized:
-		_, err := os.Stderr.WriteString("Unauthorised, please check credentials\n")
+	case http.StatusUnauthorized, http.StatusForbidden:
+		message := r.authError(resp.StatusCode, "releases", true)
+		_, err := os.Stderr.WriteString(message)
 		if err != nil {
 			panic("Cannot write to stderr")
 		}
+		r.Fail(resp.StatusCode, strings.TrimSpace(message))
 	case http.StatusNotFound:
 		_, err := os.Stderr.WriteString("Repo not found\n")
 		if err != nil {
 			panic("Cannot write to stderr")
 		}
+		r.Fail(resp.StatusCode, "repo not found")
 	case http.StatusConflict:
 		res := BadResponse{}
 		body, err := ioutil.ReadAll(resp.Body)
@@ -210,12 +281,18 @@ func (r *Properties) createRelease() bool {
 		if err != nil {
 			fmt.Println("Error unmarshalling response")
 		}
-		_, errorWriting := os.Stderr.WriteString(res.Message)
+		_, errorWriting := os.Stderr.WriteString(res.Message + "\n")
 		if errorWriting != nil {
 			panic("Cannot write to stderr")
 		}
+		r.Fail(resp.StatusCode, res.Message)
 	case http.StatusCreated:
 		createdRelease = true
+	default:
+		_, errorWriting := os.Stderr.WriteString("Error creating release, " + r.FailResponse(resp).Error() + "\n")
+		if errorWriting != nil {
+			panic("Cannot write to stderr")
+		}
 	}
 
 	return createdRelease
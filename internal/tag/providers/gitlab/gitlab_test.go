@@ -2,10 +2,14 @@ package gitlab
 
 import (
 	"github.com/sanjP10/release/internal/tag"
+	"github.com/sanjP10/release/internal/tag/faulttest"
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/h2non/gock.v1"
 	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestValidateTagNotExisting(t *testing.T) {
@@ -232,6 +236,7 @@ func TestCreateTagAndReleaseFails(t *testing.T) {
 	assertTest := assert.New(t)
 	repo := Properties{Repo: "org/repo", Host: "", RepoProperties: tag.RepoProperties{Password: "token", Tag: "test", Hash: "hash", Body: "hello"}}
 	assertTest.False(repo.CreateTag())
+	assertTest.EqualError(repo.Failure(), "status 404: repo not found")
 }
 
 func TestCreateTagOtherError(t *testing.T) {
@@ -280,7 +285,7 @@ func TestCreateReleaseNotFound(t *testing.T) {
 
 	assertTest := assert.New(t)
 	repo := Properties{Repo: "org/repo", Host: "", RepoProperties: tag.RepoProperties{Password: "token", Tag: "tag", Hash: "hash", Body: "hello"}}
-	assertTest.False(repo.createRelease())
+	assertTest.False(repo.createRelease(false))
 }
 
 func TestCreateReleaseUnauthorized(t *testing.T) {
@@ -296,7 +301,7 @@ func TestCreateReleaseUnauthorized(t *testing.T) {
 
 	assertTest := assert.New(t)
 	repo := Properties{Repo: "org/repo", Host: "", RepoProperties: tag.RepoProperties{Password: "token", Tag: "tag", Hash: "hash", Body: "hello"}}
-	assertTest.False(repo.createRelease())
+	assertTest.False(repo.createRelease(false))
 }
 
 func TestCreateRelease(t *testing.T) {
@@ -312,5 +317,108 @@ func TestCreateRelease(t *testing.T) {
 
 	assertTest := assert.New(t)
 	repo := Properties{Repo: "org/repo", Host: "", RepoProperties: tag.RepoProperties{Password: "token", Tag: "tag", Hash: "hash", Body: "hello"}}
-	assertTest.True(repo.createRelease())
+	assertTest.True(repo.createRelease(false))
+}
+
+func TestProviderFaults(t *testing.T) {
+	faulttest.Run(t, func(host string, repoProperties tag.RepoProperties) faulttest.Provider {
+		return &Properties{Repo: "repo", Host: host, RepoProperties: repoProperties}
+	})
+}
+
+func TestCreateReleaseFaults(t *testing.T) {
+	for _, fault := range faulttest.Faults {
+		if fault.ValidateOnly {
+			continue
+		}
+		fault := fault
+		t.Run(fault.Name, func(t *testing.T) {
+			// the tag is created but creating its release fails
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.Method == http.MethodGet:
+					w.WriteHeader(http.StatusNotFound)
+				case strings.HasSuffix(r.URL.Path, "/release"):
+					fault.Handler(w, r)
+				default:
+					w.WriteHeader(http.StatusCreated)
+				}
+			}))
+			defer server.Close()
+			repo := Properties{Repo: "repo", Host: server.URL, RepoProperties: tag.RepoProperties{Password: "password", Tag: "tag", Hash: "hash", Body: "hello",
+				Client: &http.Client{Timeout: faulttest.Timeout}}}
+			assert.New(t).False(repo.CreateTag())
+		})
+	}
+}
+
+func TestValidateTagRateLimited(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	gock.New("https://gitlab.com").
+		Get("/api/v4/projects/repo/repository/tags/tag").
+		Reply(http.StatusTooManyRequests)
+	assertTest := assert.New(t)
+	// Testing a 429 is reported as unknown rather than an existing tag
+	repo := Properties{Repo: "repo", Host: "", RepoProperties: tag.RepoProperties{Password: "password", Tag: "tag", Hash: "hash"}}
+	results := repo.ValidateTag()
+	assertTest.False(results.TagDoesntExist)
+	assertTest.True(results.Unknown)
+	assertTest.Equal(http.StatusTooManyRequests, results.StatusCode)
+}
+
+func TestReleaseURL(t *testing.T) {
+	assertTest := assert.New(t)
+	properties := Properties{Repo: "group/repo", RepoProperties: tag.RepoProperties{Tag: "billing/1.0.0"}}
+	assertTest.Equal("https://gitlab.com/group/repo/-/releases/billing%2F1.0.0", properties.ReleaseURL())
+	properties.Host = "https://gitlab.example.com"
+	assertTest.Equal("https://gitlab.example.com/group/repo/-/releases/billing%2F1.0.0", properties.ReleaseURL())
+}
+
+func TestCreateTagWithSlash(t *testing.T) {
+	assertTest := assert.New(t)
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.Method+" "+r.URL.EscapedPath())
+		switch r.Method {
+		case http.MethodGet:
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusCreated)
+		}
+	}))
+	defer server.Close()
+	repo := Properties{Repo: "org/repo", Host: server.URL, RepoProperties: tag.RepoProperties{Password: "token", Tag: "billing/1.3.0", Hash: "hash", Body: "hello"}}
+	assertTest.True(repo.CreateTag())
+	assertTest.Equal([]string{
+		"GET /api/v4/projects/org%2Frepo/repository/tags/billing%2F1.3.0",
+		"POST /api/v4/projects/org%2Frepo/repository/tags",
+		"POST /api/v4/projects/org%2Frepo/repository/tags/billing%2F1.3.0/release",
+	}, paths)
+}
+
+func TestCreateTagRetryFindsCreatedTagAddsRelease(t *testing.T) {
+	response := Tag{Commit: Commit{ID: "hash"}}
+	defer gock.Off() // Flush pending mocks after test execution
+
+	gock.New("https://gitlab.com/").
+		Get("api/v4/projects/org/repo/repository/tags/test").
+		Reply(http.StatusNotFound)
+	gock.New("https://gitlab.com/").
+		Post("api/v4/projects/org/repo/repository/tags").
+		Reply(http.StatusBadGateway)
+	// the failed request created the tag, so only its release is still missing
+	gock.New("https://gitlab.com/").
+		Get("api/v4/projects/org/repo/repository/tags/test").
+		Reply(http.StatusOK).
+		JSON(response)
+	gock.New("https://gitlab.com/").
+		Post("api/v4/projects/org/repo/repository/tags/test/release").
+		JSON(Release{"hello"}).
+		Reply(http.StatusCreated)
+	assertTest := assert.New(t)
+	repo := Properties{Repo: "org/repo", Host: "", RepoProperties: tag.RepoProperties{Password: "token", Tag: "test", Hash: "hash", Body: "hello",
+		Retry: tag.RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond}}}
+	assertTest.True(repo.CreateTag())
+	assertTest.True(gock.IsDone())
 }
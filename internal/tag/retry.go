@@ -0,0 +1,170 @@
+package tag
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls retries of provider API requests with exponential backoff, the zero value never retries
+type RetryPolicy struct {
+	MaxRetries int
+	BaseDelay  time.Duration
+	// MaxDelay caps the backoff, a rate limit that resets later than this is not waited for
+	MaxDelay time.Duration
+}
+
+// Retryable reports whether a failed attempt should be retried and how long to wait before doing so.
+// Network errors, 5xx responses and rate limited 403 or 429 responses are retried.
+func (p RetryPolicy) Retryable(resp *http.Response, err error, attempt int) (time.Duration, bool) {
+	if attempt >= p.MaxRetries {
+		return 0, false
+	}
+	backoff := p.backoff(attempt)
+	if err != nil {
+		return backoff, true
+	}
+	if resp == nil {
+		return 0, false
+	}
+	switch {
+	case resp.StatusCode >= http.StatusInternalServerError:
+		if delay, ok := rateLimitDelay(resp); ok {
+			return p.capped(delay)
+		}
+		return backoff, true
+	case resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode == http.StatusForbidden:
+		if delay, ok := rateLimitDelay(resp); ok {
+			return p.capped(delay)
+		}
+		if resp.StatusCode == http.StatusTooManyRequests {
+			return backoff, true
+		}
+	}
+	return 0, false
+}
+
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.BaseDelay << uint(attempt)
+	if p.MaxDelay > 0 && (delay > p.MaxDelay || delay <= 0) {
+		delay = p.MaxDelay
+	}
+	if delay > 0 {
+		// jitter avoids parallel pipelines retrying in lockstep
+		delay += time.Duration(rand.Int63n(int64(delay)/4 + 1))
+	}
+	return delay
+}
+
+func (p RetryPolicy) capped(delay time.Duration) (time.Duration, bool) {
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		return 0, false
+	}
+	return delay, true
+}
+
+// rateLimitDelay reads how long to wait from the Retry-After or X-RateLimit-Reset headers
+func rateLimitDelay(resp *http.Response) (time.Duration, bool) {
+	if retryAfter := resp.Header.Get("Retry-After"); retryAfter != "" {
+		if seconds, err := strconv.Atoi(retryAfter); err == nil {
+			return time.Duration(seconds) * time.Second, true
+		}
+		if date, err := http.ParseTime(retryAfter); err == nil {
+			return nonNegative(time.Until(date)), true
+		}
+	}
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			return nonNegative(time.Until(time.Unix(reset, 0))), true
+		}
+	}
+	return 0, false
+}
+
+func nonNegative(delay time.Duration) time.Duration {
+	if delay < 0 {
+		return 0
+	}
+	return delay
+}
+
+// Wait sleeps for the delay, returning early with the context error if it is cancelled
+func Wait(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// retryTransport retries idempotent requests according to the retry policy
+type retryTransport struct {
+	base   http.RoundTripper
+	policy RetryPolicy
+}
+
+func (t *retryTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	idempotent := request.Method == http.MethodGet || request.Method == http.MethodHead
+	for attempt := 0; ; attempt++ {
+		resp, err := t.base.RoundTrip(request)
+		if !idempotent {
+			return resp, err
+		}
+		delay, retry := t.policy.Retryable(resp, err, attempt)
+		if !retry {
+			return resp, err
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+		if waitErr := Wait(request.Context(), delay); waitErr != nil {
+			return nil, waitErr
+		}
+	}
+}
+
+// CreateWithRetry validates the tag, sends the creation request and retries it when it fails transiently.
+// The tag is validated again before each retry, so a request that was applied before failing is never sent twice
+// and a conflicting tag created in the meantime is never overwritten. When an applied request is found, applied
+// finishes the work that would have followed its response, it is nil when nothing follows.
+func (r *RepoProperties) CreateWithRetry(validate func() ValidTagState, create func() (*http.Response, error), handle func(*http.Response, error) bool, applied func() bool) bool {
+	validTagState := validate()
+	for attempt := 0; ; attempt++ {
+		if validTagState.TagExistsWithProvidedHash {
+			if attempt > 0 && applied != nil {
+				return applied()
+			}
+			return true
+		}
+		if validTagState.Unknown {
+			fmt.Println("Error validating tag", validTagState.Err())
+			return r.Fail(validTagState.StatusCode, "unable to check tag, "+validTagState.Message)
+		}
+		if !validTagState.TagDoesntExist {
+			return r.Fail(0, "tag already exists at another commit")
+		}
+		resp, err := create()
+		delay, retry := r.Retry.Retryable(resp, err, attempt)
+		if !retry {
+			return handle(resp, err)
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+		_, errorWriting := os.Stderr.WriteString("Retrying tag creation in " + delay.Round(time.Second).String() + "\n")
+		if errorWriting != nil {
+			panic("Cannot write to stderr")
+		}
+		if err := Wait(r.RequestContext(), delay); err != nil {
+			return r.FailRequest(err)
+		}
+		validTagState = validate()
+	}
+}
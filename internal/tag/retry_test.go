@@ -0,0 +1,119 @@
+package tag
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func response(status int, headers map[string]string) *http.Response {
+	resp := &http.Response{StatusCode: status, Header: http.Header{}}
+	for key, value := range headers {
+		resp.Header.Set(key, value)
+	}
+	return resp
+}
+
+func TestRetryable(t *testing.T) {
+	assertTest := assert.New(t)
+	policy := RetryPolicy{MaxRetries: 2, BaseDelay: time.Second, MaxDelay: time.Minute}
+
+	delay, retry := policy.Retryable(nil, errors.New("connection reset"), 0)
+	assertTest.True(retry)
+	assertTest.GreaterOrEqual(delay, time.Second)
+
+	delay, retry = policy.Retryable(response(http.StatusBadGateway, nil), nil, 1)
+	assertTest.True(retry)
+	assertTest.GreaterOrEqual(delay, 2*time.Second)
+
+	_, retry = policy.Retryable(response(http.StatusBadGateway, nil), nil, 2)
+	assertTest.False(retry)
+
+	_, retry = policy.Retryable(response(http.StatusNotFound, nil), nil, 0)
+	assertTest.False(retry)
+
+	_, retry = policy.Retryable(response(http.StatusForbidden, nil), nil, 0)
+	assertTest.False(retry)
+
+	delay, retry = policy.Retryable(response(http.StatusTooManyRequests, map[string]string{"Retry-After": "7"}), nil, 0)
+	assertTest.True(retry)
+	assertTest.Equal(7*time.Second, delay)
+
+	reset := strconv.FormatInt(time.Now().Add(30*time.Second).Unix(), 10)
+	delay, retry = policy.Retryable(response(http.StatusForbidden, map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": reset}), nil, 0)
+	assertTest.True(retry)
+	assertTest.InDelta(float64(30*time.Second), float64(delay), float64(2*time.Second))
+
+	// rate limits resetting beyond the longest delay fail fast
+	reset = strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)
+	_, retry = policy.Retryable(response(http.StatusForbidden, map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": reset}), nil, 0)
+	assertTest.False(retry)
+
+	_, retry = RetryPolicy{}.Retryable(nil, errors.New("connection reset"), 0)
+	assertTest.False(retry)
+}
+
+func TestRetryTransport(t *testing.T) {
+	assertTest := assert.New(t)
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, err := NewHTTPClient(ClientOptions{Retry: RetryPolicy{MaxRetries: 3, BaseDelay: time.Millisecond}})
+	assertTest.NoError(err)
+	resp, err := client.Get(server.URL)
+	assertTest.NoError(err)
+	assertTest.Equal(http.StatusOK, resp.StatusCode)
+	assertTest.Equal(3, attempts)
+
+	// creation requests are never retried by the transport
+	attempts = 0
+	resp, err = client.Post(server.URL, "application/json", nil)
+	assertTest.NoError(err)
+	assertTest.Equal(http.StatusBadGateway, resp.StatusCode)
+	assertTest.Equal(1, attempts)
+}
+
+func TestCreateWithRetry(t *testing.T) {
+	assertTest := assert.New(t)
+	r := RepoProperties{Retry: RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond}}
+	validations, creations := 0, 0
+	states := []ValidTagState{{TagDoesntExist: true}, {TagExistsWithProvidedHash: true}}
+	validate := func() ValidTagState {
+		validations++
+		return states[validations-1]
+	}
+	create := func() (*http.Response, error) {
+		creations++
+		return nil, errors.New("connection reset")
+	}
+	handle := func(*http.Response, error) bool { return false }
+	// the first attempt was applied before the connection dropped, validating again avoids a duplicate
+	assertTest.True(r.CreateWithRetry(validate, create, handle, nil))
+	assertTest.Equal(2, validations)
+	assertTest.Equal(1, creations)
+
+	validations, creations = 0, 0
+	states = []ValidTagState{{TagDoesntExist: true}, {}}
+	assertTest.False(r.CreateWithRetry(validate, create, handle, nil))
+	assertTest.Equal(1, creations)
+	// a conflicting tag created in the meantime is why the tag was not created
+	assertTest.EqualError(r.Failure(), "tag already exists at another commit")
+
+	validations, creations = 0, 0
+	states = []ValidTagState{{Unknown: true, StatusCode: http.StatusServiceUnavailable, Message: "down"}}
+	assertTest.False(r.CreateWithRetry(validate, create, handle, nil))
+	assertTest.Equal(0, creations)
+	assertTest.Equal(CreateFailure{StatusCode: http.StatusServiceUnavailable, Message: "unable to check tag, down"}, r.Failure())
+}
@@ -1,15 +1,116 @@
 package tag
 
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
 // RepoProperties properties for repo
 type RepoProperties struct {
 	Password string
 	Tag      string
 	Hash     string
 	Body     string
+	// Client used for provider API requests, a default client is used when nil
+	Client *http.Client
+	// Context cancels provider API requests, a background context is used when nil
+	Context context.Context
+	// Retry controls retrying creation requests, existing tags are validated again before each retry
+	Retry RetryPolicy
+	// failure is why the last CreateTag failed
+	failure CreateFailure
+}
+
+// CreateFailure explains why a provider did not create a tag
+type CreateFailure struct {
+	// StatusCode of the response, 0 when no response was received
+	StatusCode int
+	// Message from the provider, or of the error that stopped the request
+	Message string
+}
+
+func (f CreateFailure) Error() string {
+	if f.StatusCode == 0 {
+		return f.Message
+	}
+	return fmt.Sprintf("status %d: %s", f.StatusCode, f.Message)
 }
 
 // ValidTagState properties for repo
 type ValidTagState struct {
 	TagDoesntExist            bool
 	TagExistsWithProvidedHash bool
+	// Unknown is set when the provider could not tell whether the tag exists, such as a permissions error or outage
+	Unknown bool
+	// StatusCode of the response when the state is unknown, 0 when no response was received
+	StatusCode int
+	// Message explains why the state is unknown
+	Message string
+}
+
+// maxMessageLength limits how much of an unexpected response body is reported
+const maxMessageLength = 512
+
+// UnknownTagState is the state when a request failed before a response was received
+func UnknownTagState(err error) ValidTagState {
+	if err == nil {
+		return ValidTagState{Unknown: true, Message: "no response received"}
+	}
+	return ValidTagState{Unknown: true, Message: err.Error()}
+}
+
+// UnexpectedResponse is the state when a response does not say whether the tag exists, the body is kept as the message
+func UnexpectedResponse(resp *http.Response) ValidTagState {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxMessageLength))
+	message := strings.TrimSpace(string(body))
+	if message == "" {
+		message = http.StatusText(resp.StatusCode)
+	}
+	return ValidTagState{Unknown: true, StatusCode: resp.StatusCode, Message: message}
+}
+
+// ResponseError describes an unexpected response with its status and part of its body
+func ResponseError(resp *http.Response) error {
+	state := UnexpectedResponse(resp)
+	return fmt.Errorf("status %d: %s", state.StatusCode, state.Message)
+}
+
+// Fail records why the tag was not created, it returns false for providers to return from CreateTag
+func (r *RepoProperties) Fail(statusCode int, message string) bool {
+	r.failure = CreateFailure{StatusCode: statusCode, Message: message}
+	return false
+}
+
+// FailRequest records a request that failed before a response was received
+func (r *RepoProperties) FailRequest(err error) bool {
+	if err == nil {
+		return r.Fail(0, "no response received")
+	}
+	return r.Fail(0, err.Error())
+}
+
+// FailResponse records an unexpected response, the returned error describes it with its status and part of its body
+func (r *RepoProperties) FailResponse(resp *http.Response) error {
+	state := UnexpectedResponse(resp)
+	r.Fail(state.StatusCode, state.Message)
+	return r.failure
+}
+
+// Failure is why the last CreateTag failed
+func (r *RepoProperties) Failure() CreateFailure {
+	return r.failure
+}
+
+// Err describes why the state is unknown, nil when the provider knows whether the tag exists
+func (v ValidTagState) Err() error {
+	if !v.Unknown {
+		return nil
+	}
+	if v.StatusCode == 0 {
+		return fmt.Errorf("unable to check tag: %s", v.Message)
+	}
+	return fmt.Errorf("unable to check tag, status %d: %s", v.StatusCode, v.Message)
 }
@@ -0,0 +1,35 @@
+package tag
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestUnexpectedResponse(t *testing.T) {
+	assertTest := assert.New(t)
+	resp := &http.Response{StatusCode: http.StatusForbidden, Body: io.NopCloser(strings.NewReader(`{"message": "Resource not accessible by integration"}`))}
+	state := UnexpectedResponse(resp)
+	assertTest.True(state.Unknown)
+	assertTest.False(state.TagDoesntExist)
+	assertTest.False(state.TagExistsWithProvidedHash)
+	assertTest.Equal(http.StatusForbidden, state.StatusCode)
+	assertTest.EqualError(state.Err(), `unable to check tag, status 403: {"message": "Resource not accessible by integration"}`)
+}
+
+func TestUnexpectedResponseEmptyBody(t *testing.T) {
+	resp := &http.Response{StatusCode: http.StatusTooManyRequests, Body: io.NopCloser(strings.NewReader(""))}
+	assert.EqualError(t, UnexpectedResponse(resp).Err(), "unable to check tag, status 429: Too Many Requests")
+}
+
+func TestUnknownTagState(t *testing.T) {
+	assertTest := assert.New(t)
+	state := UnknownTagState(errors.New("connection refused"))
+	assertTest.True(state.Unknown)
+	assertTest.Equal(0, state.StatusCode)
+	assertTest.EqualError(state.Err(), "unable to check tag: connection refused")
+	assertTest.NoError(ValidTagState{TagDoesntExist: true}.Err())
+}
@@ -0,0 +1,85 @@
+package versionfile
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// jsonLocator finds a string value in a JSON file by its dotted key
+type jsonLocator struct {
+	key string
+}
+
+func (l jsonLocator) locate(content []byte) ([]value, error) {
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	path := strings.Split(l.key, ".")
+	for depth := range path {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+		if token != json.Delim('{') {
+			return nil, notFound(l.key)
+		}
+		if !seekJSONKey(decoder, path[depth]) {
+			return nil, notFound(l.key)
+		}
+	}
+	afterKey := int(decoder.InputOffset())
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	version, ok := token.(string)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a string", l.key)
+	}
+	// the value starts at the first quote after the colon following the key
+	start := afterKey + bytes.IndexByte(content[afterKey:], '"')
+	return []value{{start: start, end: int(decoder.InputOffset()), version: version, encode: jsonString}}, nil
+}
+
+// seekJSONKey reads the keys of the object until the key, skipping the values of other keys
+func seekJSONKey(decoder *json.Decoder, key string) bool {
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return false
+		}
+		if token == key {
+			return true
+		}
+		if skipJSONValue(decoder) != nil {
+			return false
+		}
+	}
+	return false
+}
+
+// skipJSONValue reads the next value, including everything nested in it
+func skipJSONValue(decoder *json.Decoder) error {
+	depth := 0
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		switch token {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+// jsonString encodes the version as a JSON string
+func jsonString(version string) string {
+	encoded, _ := json.Marshal(version)
+	return string(encoded)
+}
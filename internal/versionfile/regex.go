@@ -0,0 +1,30 @@
+package versionfile
+
+import (
+	"errors"
+	"regexp"
+)
+
+// regexLocator finds the first group of every match of a pattern
+type regexLocator struct {
+	pattern *regexp.Regexp
+}
+
+func (l regexLocator) locate(content []byte) ([]value, error) {
+	var values []value
+	for _, match := range l.pattern.FindAllSubmatchIndex(content, -1) {
+		if match[2] < 0 {
+			continue
+		}
+		values = append(values, value{
+			start:   match[2],
+			end:     match[3],
+			version: string(content[match[2]:match[3]]),
+			encode:  func(version string) string { return version },
+		})
+	}
+	if len(values) == 0 {
+		return nil, errors.New("pattern " + l.pattern.String() + " does not match")
+	}
+	return values, nil
+}
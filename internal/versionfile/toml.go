@@ -0,0 +1,57 @@
+package versionfile
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+)
+
+var tomlTableRegex = regexp.MustCompile(`^\s*\[\[?([^\[\]]+)\]\]?\s*(#.*)?$`)
+var tomlStringRegex = regexp.MustCompile(`^\s*([A-Za-z0-9_\-."' ]+?)\s*=\s*("[^"\\\n]*"|'[^'\n]*')`)
+
+// tomlLocator finds a single line string in a TOML file by its dotted key, the table headers before it and dotted
+// keys are combined into the key
+type tomlLocator struct {
+	key string
+}
+
+func (l tomlLocator) locate(content []byte) ([]value, error) {
+	table := ""
+	offset := 0
+	for _, line := range bytes.SplitAfter(content, []byte("\n")) {
+		lineStart := offset
+		offset += len(line)
+		if header := tomlTableRegex.FindSubmatch(line); header != nil {
+			table = tomlKey(string(header[1]))
+			continue
+		}
+		match := tomlStringRegex.FindSubmatchIndex(line)
+		if match == nil {
+			continue
+		}
+		key := tomlKey(string(line[match[2]:match[3]]))
+		if table != "" {
+			key = table + "." + key
+		}
+		if key != l.key {
+			continue
+		}
+		quote := string(line[match[4]])
+		return []value{{
+			start:   lineStart + match[4],
+			end:     lineStart + match[5],
+			version: string(line[match[4]+1 : match[5]-1]),
+			encode:  func(version string) string { return quote + version + quote },
+		}}, nil
+	}
+	return nil, notFound(l.key)
+}
+
+// tomlKey normalises a dotted key by removing the spaces and quotes around its parts
+func tomlKey(key string) string {
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		parts[i] = strings.Trim(strings.TrimSpace(part), `"'`)
+	}
+	return strings.Join(parts, ".")
+}
@@ -0,0 +1,209 @@
+// Package versionfile reads and updates the version held in project files, leaving the rest of each file untouched
+package versionfile
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Formats of version files
+const (
+	FormatJSON  = "json"
+	FormatYAML  = "yaml"
+	FormatTOML  = "toml"
+	FormatRegex = "regex"
+)
+
+// File is a project file holding the version
+type File struct {
+	// Path of the file, a glob matches several files
+	Path string
+	// Format of the file, detected from the extension when empty and regex when a pattern is set
+	Format string
+	// Key is the dotted path of the version in JSON, YAML and TOML files, defaults to version, project.version for
+	// pyproject.toml and package.version for Cargo.toml
+	Key string
+	// Pattern is a regular expression whose first group holds the version, used by the regex format
+	Pattern string
+}
+
+// value is where a version is held in the content of a file
+type value struct {
+	start   int
+	end     int
+	version string
+	// encode writes a version the way the value is written, such as quoted
+	encode func(version string) string
+}
+
+// locator finds the values holding the version in the content of a file
+type locator interface {
+	locate(content []byte) ([]value, error)
+}
+
+// Expand resolves the glob in the path of each file, a glob matching no files is an error
+func Expand(files []File) ([]File, error) {
+	var expanded []File
+	for _, file := range files {
+		if !strings.ContainsAny(file.Path, "*?[") {
+			expanded = append(expanded, file)
+			continue
+		}
+		matches, err := filepath.Glob(file.Path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version file glob %s: %w", file.Path, err)
+		}
+		if len(matches) == 0 {
+			return nil, errors.New("no version files match " + file.Path)
+		}
+		sort.Strings(matches)
+		for _, match := range matches {
+			matched := file
+			matched.Path = match
+			expanded = append(expanded, matched)
+		}
+	}
+	return expanded, nil
+}
+
+// Validate checks the format, key and pattern of the file without reading it
+func (f File) Validate() error {
+	_, err := f.locator()
+	return err
+}
+
+// Read the version held in the file, every value holding it must agree
+func (f File) Read() (string, error) {
+	_, values, err := f.values()
+	if err != nil {
+		return "", err
+	}
+	for _, current := range values[1:] {
+		if current.version != values[0].version {
+			return "", fmt.Errorf("%s holds different versions %s and %s", f.Path, values[0].version, current.version)
+		}
+	}
+	return values[0].version, nil
+}
+
+// Write the version to the file, reporting whether the file changed
+func (f File) Write(version string) (bool, error) {
+	content, values, err := f.values()
+	if err != nil {
+		return false, err
+	}
+	updated := append([]byte{}, content...)
+	changed := false
+	// values are replaced from the end so the positions of earlier values stay valid
+	for i := len(values) - 1; i >= 0; i-- {
+		current := values[i]
+		if current.version == version {
+			continue
+		}
+		changed = true
+		updated = append(updated[:current.start], append([]byte(current.encode(version)), updated[current.end:]...)...)
+	}
+	if !changed {
+		return false, nil
+	}
+	info, err := os.Stat(f.Path)
+	if err != nil {
+		return false, err
+	}
+	return true, os.WriteFile(f.Path, updated, info.Mode().Perm())
+}
+
+// values reads the file and finds the values holding the version
+func (f File) values() ([]byte, []value, error) {
+	locator, err := f.locator()
+	if err != nil {
+		return nil, nil, err
+	}
+	content, err := os.ReadFile(f.Path)
+	if err != nil {
+		return nil, nil, err
+	}
+	values, err := locator.locate(content)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", f.Path, err)
+	}
+	return content, values, nil
+}
+
+// locator for the format of the file
+func (f File) locator() (locator, error) {
+	format := strings.ToLower(f.Format)
+	if format == "" {
+		format = detectFormat(f.Path, f.Pattern)
+	}
+	if format != FormatRegex && f.Pattern != "" {
+		return nil, fmt.Errorf("%s: a pattern is only used by the regex format", f.Path)
+	}
+	if format == FormatRegex && f.Key != "" {
+		return nil, fmt.Errorf("%s: a key is not used by the regex format", f.Path)
+	}
+	key := f.Key
+	if key == "" {
+		key = defaultKey(f.Path)
+	}
+	switch format {
+	case FormatJSON:
+		return jsonLocator{key: key}, nil
+	case FormatYAML:
+		return yamlLocator{key: key}, nil
+	case FormatTOML:
+		return tomlLocator{key: key}, nil
+	case FormatRegex:
+		if f.Pattern == "" {
+			return nil, fmt.Errorf("%s: the regex format requires a pattern", f.Path)
+		}
+		pattern, err := regexp.Compile(f.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid pattern: %w", f.Path, err)
+		}
+		if pattern.NumSubexp() < 1 {
+			return nil, fmt.Errorf("%s: pattern %s must have a group holding the version", f.Path, f.Pattern)
+		}
+		return regexLocator{pattern: pattern}, nil
+	case "":
+		return nil, fmt.Errorf("%s: unknown format, set a format of json, yaml, toml or regex", f.Path)
+	}
+	return nil, fmt.Errorf("%s: format %s is not one of json, yaml, toml or regex", f.Path, f.Format)
+}
+
+// detectFormat from the extension of the path, a pattern selects the regex format
+func detectFormat(path string, pattern string) string {
+	if pattern != "" {
+		return FormatRegex
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return FormatJSON
+	case ".yaml", ".yml":
+		return FormatYAML
+	case ".toml":
+		return FormatTOML
+	}
+	return ""
+}
+
+// defaultKey of the version for well known files
+func defaultKey(path string) string {
+	switch filepath.Base(path) {
+	case "pyproject.toml":
+		return "project.version"
+	case "Cargo.toml":
+		return "package.version"
+	}
+	return "version"
+}
+
+// notFound is the error of a key missing from a file
+func notFound(key string) error {
+	return errors.New("key " + key + " not found")
+}
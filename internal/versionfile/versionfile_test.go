@@ -0,0 +1,149 @@
+package versionfile
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFile(t *testing.T, dir string, name string, content string) string {
+	path := filepath.Join(dir, name)
+	err := os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(path, []byte(content), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadWrite(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     File
+		content  string
+		expected string
+	}{
+		{
+			name:     "package.json",
+			file:     File{},
+			content:  "{\n  \"name\": \"app\",\n  \"scripts\": {\"version\": \"x\"},\n  \"version\" : \"1.1.0\",\n  \"private\": true\n}\n",
+			expected: "{\n  \"name\": \"app\",\n  \"scripts\": {\"version\": \"x\"},\n  \"version\" : \"1.2.0\",\n  \"private\": true\n}\n",
+		},
+		{
+			name:     "nested.json",
+			file:     File{Key: "metadata.version"},
+			content:  `{"version": "0.0.1", "metadata": {"tags": ["a", {"b": 1}], "version": "1.1.0"}}`,
+			expected: `{"version": "0.0.1", "metadata": {"tags": ["a", {"b": 1}], "version": "1.2.0"}}`,
+		},
+		{
+			name:     "pyproject.toml",
+			file:     File{},
+			content:  "[build-system]\nversion = \"0.1\"\n\n[project]\nname = \"app\"\nversion = \"1.1.0\" # released\n",
+			expected: "[build-system]\nversion = \"0.1\"\n\n[project]\nname = \"app\"\nversion = \"1.2.0\" # released\n",
+		},
+		{
+			name:     "poetry.toml",
+			file:     File{Key: "tool.poetry.version"},
+			content:  "[tool . \"poetry\"]\nversion = '1.1.0'\n",
+			expected: "[tool . \"poetry\"]\nversion = '1.2.0'\n",
+		},
+		{
+			name:     "dotted.toml",
+			file:     File{Key: "package.version"},
+			content:  "package.version = \"1.1.0\"\n",
+			expected: "package.version = \"1.2.0\"\n",
+		},
+		{
+			name:     "Chart.yaml",
+			file:     File{},
+			content:  "apiVersion: v2\nname: app # chart\nversion: 1.1.0 # chart version\nappVersion: \"1.1.0\"\n",
+			expected: "apiVersion: v2\nname: app # chart\nversion: 1.2.0 # chart version\nappVersion: \"1.1.0\"\n",
+		},
+		{
+			name:     "values.yml",
+			file:     File{Key: "image.tag"},
+			content:  "image:\n  repository: app\n  tag: '1.1.0'\n",
+			expected: "image:\n  repository: app\n  tag: '1.2.0'\n",
+		},
+		{
+			name:     "version.go",
+			file:     File{Pattern: `Version = "([^"]+)"`},
+			content:  "package main\n\n// Version of the app\nconst Version = \"1.1.0\"\n",
+			expected: "package main\n\n// Version of the app\nconst Version = \"1.2.0\"\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assertTest := assert.New(t)
+			file := test.file
+			file.Path = writeFile(t, t.TempDir(), test.name, test.content)
+			version, err := file.Read()
+			assertTest.NoError(err)
+			assertTest.Equal("1.1.0", version)
+
+			changed, err := file.Write("1.2.0")
+			assertTest.NoError(err)
+			assertTest.True(changed)
+			content, _ := os.ReadFile(file.Path)
+			assertTest.Equal(test.expected, string(content))
+
+			changed, err = file.Write("1.2.0")
+			assertTest.NoError(err)
+			assertTest.False(changed)
+		})
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	assertTest := assert.New(t)
+	dir := t.TempDir()
+	_, err := File{Path: writeFile(t, dir, "package.json", `{"name": "app"}`)}.Read()
+	assertTest.ErrorContains(err, "key version not found")
+	_, err = File{Path: writeFile(t, dir, "number.json", `{"version": 1}`)}.Read()
+	assertTest.ErrorContains(err, "version is not a string")
+	_, err = File{Path: writeFile(t, dir, "Chart.yaml", "version: |\n  1.0.0\n")}.Read()
+	assertTest.ErrorContains(err, "must be a plain or quoted scalar")
+	_, err = File{Path: writeFile(t, dir, "version.txt", "1.0.0")}.Read()
+	assertTest.ErrorContains(err, "unknown format")
+	_, err = File{Path: writeFile(t, dir, "two.go", `A = "1.0.0"; B = "1.1.0"`), Pattern: `= "([^"]+)"`}.Read()
+	assertTest.ErrorContains(err, "holds different versions 1.0.0 and 1.1.0")
+	_, err = File{Path: filepath.Join(dir, "app.go"), Pattern: "version"}.Read()
+	assertTest.ErrorContains(err, "must have a group")
+	assertTest.ErrorContains(File{Path: "app.json", Format: "json", Pattern: "(v)"}.Validate(), "only used by the regex format")
+	assertTest.ErrorContains(File{Path: "app", Format: "ini"}.Validate(), "format ini is not one of")
+	assertTest.NoError(File{Path: "VERSION", Pattern: "(.+)"}.Validate())
+}
+
+func TestWriteAllMatches(t *testing.T) {
+	assertTest := assert.New(t)
+	path := writeFile(t, t.TempDir(), "README.md", "install v1.1.0\n\nor download v1.1.0\n")
+	changed, err := File{Path: path, Pattern: `v(\d+\.\d+\.\d+)`}.Write("1.10.0")
+	assertTest.NoError(err)
+	assertTest.True(changed)
+	content, _ := os.ReadFile(path)
+	assertTest.Equal("install v1.10.0\n\nor download v1.10.0\n", string(content))
+}
+
+func TestExpand(t *testing.T) {
+	assertTest := assert.New(t)
+	dir := t.TempDir()
+	writeFile(t, dir, "charts/b/Chart.yaml", "version: 1.0.0\n")
+	writeFile(t, dir, "charts/a/Chart.yaml", "version: 1.0.0\n")
+	files, err := Expand([]File{
+		{Path: filepath.Join(dir, "charts", "*", "Chart.yaml"), Key: "version"},
+		{Path: filepath.Join(dir, "package.json")},
+	})
+	assertTest.NoError(err)
+	assertTest.Equal([]File{
+		{Path: filepath.Join(dir, "charts", "a", "Chart.yaml"), Key: "version"},
+		{Path: filepath.Join(dir, "charts", "b", "Chart.yaml"), Key: "version"},
+		{Path: filepath.Join(dir, "package.json")},
+	}, files)
+
+	_, err = Expand([]File{{Path: filepath.Join(dir, "*.toml")}})
+	assertTest.ErrorContains(err, "no version files match")
+}
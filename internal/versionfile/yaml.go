@@ -0,0 +1,111 @@
+package versionfile
+
+import (
+	"bytes"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"strings"
+)
+
+// yamlLocator finds a scalar in the first document of a YAML file by its dotted key
+type yamlLocator struct {
+	key string
+}
+
+func (l yamlLocator) locate(content []byte) ([]value, error) {
+	document := yaml.Node{}
+	err := yaml.Unmarshal(content, &document)
+	if err != nil {
+		return nil, fmt.Errorf("invalid YAML: %w", err)
+	}
+	if len(document.Content) == 0 {
+		return nil, notFound(l.key)
+	}
+	node := document.Content[0]
+	for _, part := range strings.Split(l.key, ".") {
+		if node.Kind != yaml.MappingNode {
+			return nil, notFound(l.key)
+		}
+		var next *yaml.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == part {
+				next = node.Content[i+1]
+			}
+		}
+		if next == nil {
+			return nil, notFound(l.key)
+		}
+		node = next
+	}
+	if node.Kind != yaml.ScalarNode || node.Style&(yaml.LiteralStyle|yaml.FoldedStyle|yaml.TaggedStyle) != 0 {
+		return nil, fmt.Errorf("%s must be a plain or quoted scalar", l.key)
+	}
+	start := yamlOffset(content, node.Line, node.Column)
+	if start < 0 {
+		return nil, fmt.Errorf("unable to find %s", l.key)
+	}
+	found := value{start: start, version: node.Value}
+	switch {
+	case node.Style&yaml.DoubleQuotedStyle != 0:
+		found.end = closingQuote(content, start, '"')
+		found.encode = func(version string) string { return `"` + version + `"` }
+	case node.Style&yaml.SingleQuotedStyle != 0:
+		found.end = closingQuote(content, start, '\'')
+		found.encode = func(version string) string { return "'" + version + "'" }
+	default:
+		found.end = plainEnd(content, start)
+		found.encode = func(version string) string { return version }
+	}
+	if found.end < 0 {
+		return nil, fmt.Errorf("unable to find the end of %s", l.key)
+	}
+	return []value{found}, nil
+}
+
+// yamlOffset converts the 1-based line and column of a node, counted in characters, to a byte offset
+func yamlOffset(content []byte, line int, column int) int {
+	offset := 0
+	for current := 1; current < line; current++ {
+		next := bytes.IndexByte(content[offset:], '\n')
+		if next < 0 {
+			return -1
+		}
+		offset += next + 1
+	}
+	for i := range string(content[offset:]) {
+		if column == 1 {
+			return offset + i
+		}
+		column--
+	}
+	return -1
+}
+
+// closingQuote finds the end of the quoted scalar starting at the offset, past its closing quote. Single quotes are
+// escaped by doubling them and double quotes by a backslash
+func closingQuote(content []byte, start int, quote byte) int {
+	for i := start + 1; i < len(content); i++ {
+		switch {
+		case quote == '"' && content[i] == '\\':
+			i++
+		case content[i] == quote && quote == '\'' && i+1 < len(content) && content[i+1] == '\'':
+			i++
+		case content[i] == quote:
+			return i + 1
+		}
+	}
+	return -1
+}
+
+// plainEnd finds the end of the plain scalar starting at the offset, before a comment or the end of the line
+func plainEnd(content []byte, start int) int {
+	end := bytes.IndexByte(content[start:], '\n')
+	if end < 0 {
+		end = len(content) - start
+	}
+	line := string(content[start : start+end])
+	if comment := strings.Index(line, " #"); comment >= 0 {
+		line = line[:comment]
+	}
+	return start + len(strings.TrimRight(line, " \t\r"))
+}
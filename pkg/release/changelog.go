@@ -0,0 +1,122 @@
+package release
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Errors of validating a changelog
+var (
+	ErrNoVersion           = errors.New("changelog has no version")
+	ErrVersionNotIncreased = errors.New("latest version must be greater than the previous version")
+)
+
+var sectionRegex = regexp.MustCompile("^##([^#].*)?$")
+var versionRegex = regexp.MustCompile("^##\\s*\\d.+")
+var unreleasedRegex = regexp.MustCompile("(?i)^##\\s*\\[?unreleased\\]?\\s*$")
+var headingMarkupRegex = regexp.MustCompile("##|\\s*")
+
+// Entry is a version of a changelog
+type Entry struct {
+	// Version from the ## heading, such as 1.2.0
+	Version string
+	// Notes are the markdown changes of the version without blank lines, used as the release notes
+	Notes string
+}
+
+// Changelog is a markdown changelog with a ## heading for each version, newest first
+type Changelog struct {
+	// Unreleased are the notes of the ## Unreleased section
+	Unreleased string
+	// Entries of each version, newest first
+	Entries []Entry
+}
+
+// ParseChangelog reads the versions and their notes from the markdown of a changelog, text before the first ##
+// heading such as the title is left out
+func ParseChangelog(markdown string) Changelog {
+	parsed := Changelog{}
+	heading := ""
+	var body []string
+	add := func() {
+		switch {
+		case unreleasedRegex.MatchString(heading):
+			parsed.Unreleased = notes(body)
+		case versionRegex.MatchString(heading):
+			parsed.Entries = append(parsed.Entries, Entry{Version: headingMarkupRegex.ReplaceAllString(heading, ""), Notes: notes(body)})
+		}
+	}
+	scanner := bufio.NewScanner(strings.NewReader(markdown))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t")
+		if sectionRegex.MatchString(line) {
+			add()
+			heading, body = line, nil
+			continue
+		}
+		body = append(body, line)
+	}
+	add()
+	return parsed
+}
+
+// ReadChangelog reads and parses the changelog file
+func ReadChangelog(path string) (Changelog, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return Changelog{}, err
+	}
+	return ParseChangelog(string(content)), nil
+}
+
+// Latest is the newest version, the version being released
+func (c Changelog) Latest() (Entry, bool) {
+	if len(c.Entries) == 0 {
+		return Entry{}, false
+	}
+	return c.Entries[0], true
+}
+
+// Previous is the version before the latest version
+func (c Changelog) Previous() (Entry, bool) {
+	if len(c.Entries) < 2 {
+		return Entry{}, false
+	}
+	return c.Entries[1], true
+}
+
+// Validate checks the changelog has a version and the latest version is greater than the previous version, a first
+// version is accepted as it is
+func (c Changelog) Validate() error {
+	latest, ok := c.Latest()
+	if !ok {
+		return ErrNoVersion
+	}
+	previous, ok := c.Previous()
+	if !ok {
+		return nil
+	}
+	compared, err := CompareVersions(latest.Version, previous.Version)
+	if err != nil {
+		return err
+	}
+	if compared <= 0 {
+		return fmt.Errorf("%w, %s is not greater than %s", ErrVersionNotIncreased, latest.Version, previous.Version)
+	}
+	return nil
+}
+
+// notes removes the blank lines of a section
+func notes(body []string) string {
+	var lines []string
+	for _, line := range body {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
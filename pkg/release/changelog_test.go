@@ -0,0 +1,75 @@
+package release
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestReadChangelog(t *testing.T) {
+	assertTest := assert.New(t)
+	parsed, err := ReadChangelog("../../fixtures/Changelog.md")
+	assertTest.NoError(err)
+	assertTest.Equal([]Entry{
+		{Version: "1.1.0", Notes: "### Updated\n* An update happened"},
+		{Version: "1.0.0", Notes: "### Added\n* Initial release"},
+	}, parsed.Entries)
+	assertTest.NoError(parsed.Validate())
+
+	first, err := ReadChangelog("../../fixtures/FirstChangelog.md")
+	assertTest.NoError(err)
+	assertTest.Equal([]Entry{{Version: "0.0.0", Notes: "### Added\n* Initial release"}}, first.Entries)
+
+	_, err = ReadChangelog("../../fixtures/Missing.md")
+	assertTest.Error(err)
+}
+
+func TestParseChangelogUnreleased(t *testing.T) {
+	assertTest := assert.New(t)
+	parsed := ParseChangelog("# Changelog\n\n## [Unreleased]\n* Coming soon\n\n## 0.0.1\n* First\n")
+	assertTest.Equal("* Coming soon", parsed.Unreleased)
+	latest, ok := parsed.Latest()
+	assertTest.True(ok)
+	assertTest.Equal(Entry{Version: "0.0.1", Notes: "* First"}, latest)
+	_, ok = parsed.Previous()
+	assertTest.False(ok)
+	assertTest.NoError(parsed.Validate())
+}
+
+func TestChangelogValidate(t *testing.T) {
+	assertTest := assert.New(t)
+	assertTest.ErrorIs(ParseChangelog("# Changelog\n").Validate(), ErrNoVersion)
+
+	err := ParseChangelog("## 1.0.0\n* Down\n## 1.1.0\n* Up\n").Validate()
+	assertTest.True(errors.Is(err, ErrVersionNotIncreased))
+	assertTest.EqualError(err, "latest version must be greater than the previous version, 1.0.0 is not greater than 1.1.0")
+
+	assertTest.ErrorIs(ParseChangelog("## 1.10.0\n## 2.0.1\n").Validate(), ErrVersionNotIncreased)
+	assertTest.NoError(ParseChangelog("## 1.0.0.0\n## 0.0.1.0\n").Validate())
+	assertTest.ErrorContains(ParseChangelog("## 1.0.0\n## 1.x\n").Validate(), "invalid version 1.x")
+	// a first version is not compared with anything
+	assertTest.NoError(ParseChangelog("## 2024.1-beta_1\n* First\n").Validate())
+}
+
+func TestCompareVersions(t *testing.T) {
+	assertTest := assert.New(t)
+	compared, err := CompareVersions("1.10.0", "1.9.0")
+	assertTest.NoError(err)
+	assertTest.Equal(1, compared)
+	compared, _ = CompareVersions("2.0.0-rc.1", "2.0.0")
+	assertTest.Equal(-1, compared)
+	compared, _ = CompareVersions("1.0", "1.0.0")
+	assertTest.Equal(0, compared)
+	_, err = CompareVersions("1.0.0", "latest")
+	assertTest.ErrorContains(err, "invalid version latest")
+}
+
+func TestParseChangelogHeadings(t *testing.T) {
+	assertTest := assert.New(t)
+	parsed := ParseChangelog("# Changelog\n\n## Unreleased\n\n##    1.1.0   \n### Updated\n* An update happened\n\n##1.0.0\n#### Added\n* Initial release\n")
+	assertTest.Empty(parsed.Unreleased)
+	assertTest.Equal([]Entry{
+		{Version: "1.1.0", Notes: "### Updated\n* An update happened"},
+		{Version: "1.0.0", Notes: "#### Added\n* Initial release"},
+	}, parsed.Entries)
+}
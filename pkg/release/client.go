@@ -0,0 +1,520 @@
+package release
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/sanjP10/release/internal/tag"
+	"github.com/sanjP10/release/internal/tag/providers/bitbucket"
+	"github.com/sanjP10/release/internal/tag/providers/git"
+	"github.com/sanjP10/release/internal/tag/providers/github"
+	"github.com/sanjP10/release/internal/tag/providers/gitlab"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Errors wrapped by a ProviderError
+var (
+	ErrCheckTag  = errors.New("unable to check tag")
+	ErrCreateTag = errors.New("unable to create tag")
+)
+
+// ProviderError is a request the provider rejected or that failed before it responded
+type ProviderError struct {
+	// Err is ErrCheckTag or ErrCreateTag
+	Err error
+	// StatusCode of the response, 0 when no response was received
+	StatusCode int
+	// Message from the provider, or of the error that stopped the request
+	Message string
+}
+
+func (e *ProviderError) Error() string {
+	if e.StatusCode == 0 {
+		return fmt.Sprintf("%s: %s", e.Err, e.Message)
+	}
+	return fmt.Sprintf("%s, status %d: %s", e.Err, e.StatusCode, e.Message)
+}
+
+func (e *ProviderError) Unwrap() error {
+	return e.Err
+}
+
+// Tag to validate or create
+type Tag struct {
+	// Name of the tag, such as 1.2.0
+	Name string
+	// Hash is the full hash of the commit the tag points at
+	Hash string
+	// Notes are the release notes, used by providers that create releases or annotated tags
+	Notes string
+}
+
+// TagState is whether a tag exists and where it points
+type TagState int
+
+// States of a tag
+const (
+	// TagMissing is a tag that does not exist and can be created
+	TagMissing TagState = iota
+	// TagAtHash is a tag that already exists at the hash, creating it again succeeds without changes
+	TagAtHash
+	// TagAtOtherCommit is a tag that already exists at another commit, the version needs to be bumped
+	TagAtOtherCommit
+)
+
+// Client validates and creates tags with a Git provider. Clients are safe for concurrent use, each call of a GitHub,
+// GitLab or Bitbucket client makes its own requests
+type Client interface {
+	// ValidateTag reports whether the tag exists, a ProviderError means the provider could not tell, such as a
+	// permissions error or outage
+	ValidateTag(ctx context.Context, tag Tag) (TagState, error)
+	// CreateTag creates the tag, or the release for providers that create releases. A tag already at the hash is not
+	// an error, a ProviderError explains why the tag was not created
+	CreateTag(ctx context.Context, tag Tag) error
+	// ChangedFiles lists the paths, relative to the root of the repository, changed between the commit of the existing
+	// tag and the hash of the tag
+	ChangedFiles(ctx context.Context, tag Tag) ([]string, error)
+}
+
+// ReleaseLinker is implemented by clients of providers with a web page for each release
+type ReleaseLinker interface {
+	// ReleaseURL is the page of the release of the tag, or of the tag when the provider has no releases
+	ReleaseURL(tag Tag) string
+}
+
+// SignatureVerifier is implemented by clients that can check who signed an existing tag
+type SignatureVerifier interface {
+	// VerifyTag checks the existing tag is signed by a key in the keyring file, an armored OpenPGP keyring or an SSH
+	// allowed signers file
+	VerifyTag(ctx context.Context, tag Tag, keyring string) error
+}
+
+// ErrFileNotFound is returned by a FileReader when the file does not exist at the ref
+var ErrFileNotFound = tag.ErrFileNotFound
+
+// FileReader is implemented by clients that can read files of the repository
+type FileReader interface {
+	// ReadFile reads the file at the path, relative to the root of the repository, at a branch, tag or commit
+	ReadFile(ctx context.Context, ref string, path string) (string, error)
+}
+
+// Commenter is implemented by clients of providers with pull requests
+type Commenter interface {
+	// UpsertComment adds the body, which starts with the marker, as a comment on the pull request, or updates the
+	// comment starting with the marker
+	UpsertComment(ctx context.Context, pullRequest int, marker string, body string) error
+}
+
+// RetryPolicy retries requests failing with network errors, 5xx responses or rate limits with exponential backoff,
+// the zero value never retries
+type RetryPolicy struct {
+	MaxRetries int
+	// BaseDelay is the wait before the first retry, doubled for each retry
+	BaseDelay time.Duration
+	// MaxDelay caps the wait, a rate limit that resets later than this is not waited for
+	MaxDelay time.Duration
+}
+
+// HTTPOptions configure the API requests of GitHub, GitLab and Bitbucket clients
+type HTTPOptions struct {
+	// Client sends the requests. When nil a client is created that reads proxies from HTTPS_PROXY and NO_PROXY,
+	// limits each attempt to Timeout and retries reads with Retry
+	Client *http.Client
+	// Timeout of each attempt of a request sent by the created client, attempts are not limited when zero
+	Timeout time.Duration
+	// Retry retries creating a tag, the tag is validated again before each retry so it is never created twice
+	Retry RetryPolicy
+}
+
+// GitHubOptions configure a GitHub client
+type GitHubOptions struct {
+	// Repo is owner/repo
+	Repo string
+	// Host of GitHub Enterprise Server such as https://github.example.com, github.com when empty
+	Host string
+	// Username with Token authenticates with basic authentication, Token alone is sent as a bearer token
+	Username string
+	Token    string
+	// AppID and AppKey, the private key file of the app, authenticate as a GitHub App instead of with a token. The
+	// installation is looked up from Repo when InstallationID is empty
+	AppID          string
+	AppKey         string
+	InstallationID string
+	HTTP           HTTPOptions
+}
+
+// Token types of GitLab
+const (
+	GitLabPrivateToken = gitlab.AuthPrivateToken
+	GitLabJobToken     = gitlab.AuthJobToken
+	GitLabOAuthToken   = gitlab.AuthBearer
+)
+
+// GitLabOptions configure a GitLab client
+type GitLabOptions struct {
+	// Repo is the path of the project such as group/project
+	Repo string
+	// Host of a self-hosted instance such as https://gitlab.example.com, gitlab.com when empty
+	Host string
+	// Token is a personal, project or group access token, a CI job token or an OAuth token
+	Token string
+	// TokenType of Token. When empty the CI_JOB_TOKEN of GitLab CI is used as a job token if Token is empty or the
+	// same, otherwise Token is a private token
+	TokenType string
+	HTTP      HTTPOptions
+}
+
+// BitbucketOptions configure a Bitbucket Cloud or Bitbucket Server client
+type BitbucketOptions struct {
+	// Repo is workspace/repo on Bitbucket Cloud, project/repo or ~user/repo on Bitbucket Server
+	Repo string
+	// Host of Bitbucket Server such as https://bitbucket.example.com, Bitbucket Cloud when empty
+	Host string
+	// Username with Password authenticates with basic authentication, Password alone is sent as a bearer token
+	Username string
+	Password string
+	HTTP     HTTPOptions
+}
+
+// Signing formats of git tags
+const (
+	SignFormatOpenPGP = git.SignFormatOpenPGP
+	SignFormatSSH     = git.SignFormatSSH
+)
+
+// GitOptions configure a client using git with any origin
+type GitOptions struct {
+	// Origin is the HTTPS or SSH URL of the repository, the origin of RepoPath when empty
+	Origin string
+	// RepoPath is an existing local clone to use instead of fetching the origin into memory
+	RepoPath string
+	// Username and Password for HTTPS origins, or the passphrase of SSHKey. Without them git credential helpers or
+	// netrc are used for HTTPS origins
+	Username string
+	Password string
+	// BearerToken sends Password to HTTPS origins as a bearer token instead of with basic authentication
+	BearerToken bool
+	// SSHKey is a private key file for SSH origins, ssh-agent is used when empty
+	SSHKey string
+	// KnownHosts is a known_hosts file verifying SSH host keys instead of the defaults
+	KnownHosts string
+	// InsecureIgnoreHostKey disables verifying SSH host keys
+	InsecureIgnoreHostKey bool
+	// Email recorded as the tagger of annotated tags
+	Email string
+	// TaggerName and TaggerEmail recorded on annotated tags instead of Username and Email
+	TaggerName  string
+	TaggerEmail string
+	// Lightweight creates tags pointing straight at the commit instead of annotated tags with the notes
+	Lightweight bool
+	// SignKey is the private key file signing annotated tags in SignFormat, SignFormatOpenPGP when empty, unlocked
+	// with SignPassword
+	SignKey      string
+	SignFormat   string
+	SignPassword string
+}
+
+// tagProvider is implemented by each provider of the release tool
+type tagProvider interface {
+	ValidateTag() tag.ValidTagState
+	CreateTag() bool
+	Failure() tag.CreateFailure
+	tag.ChangeLister
+	tag.FileReader
+}
+
+// providerClient creates a provider for each request
+type providerClient struct {
+	client   *http.Client
+	retry    RetryPolicy
+	provider func(properties tag.RepoProperties) (tagProvider, error)
+}
+
+// hostedClient is a client of a provider API with release pages
+type hostedClient struct {
+	providerClient
+}
+
+// gitClient is a client pushing tags with git
+type gitClient struct {
+	providerClient
+	// repository builds the provider without contacting the origin
+	repository func(properties tag.RepoProperties) *git.Properties
+	files      *gitFiles
+	tags       *gitTags
+}
+
+// gitTags is the repository the refs of the origin were listed into for a tag and hash, reused by the next call for
+// the same tag so validating then creating it lists the refs once. Creating the tag changes the refs so it is dropped
+type gitTags struct {
+	sync.Mutex
+	key      string
+	provider *git.Properties
+	// list the refs of the origin into the repository
+	list func(provider *git.Properties) error
+}
+
+// gitFiles is the repository files are read from, opened on the first read and kept so fetched commits are reused
+type gitFiles struct {
+	sync.Mutex
+	provider *git.Properties
+}
+
+// NewGitHub returns a client creating releases on GitHub
+func NewGitHub(options GitHubOptions) (Client, error) {
+	if options.Repo == "" {
+		return nil, errors.New("a GitHub repo is required")
+	}
+	authType := authType(options.Username, github.AuthBasic, github.AuthBearer)
+	if options.AppID != "" {
+		authType = github.AuthApp
+	}
+	client, err := newProviderClient(options.HTTP, func(properties tag.RepoProperties) (tagProvider, error) {
+		properties.Password = options.Token
+		return &github.Properties{Username: options.Username, Repo: options.Repo, Host: strings.TrimSuffix(options.Host, "/"),
+			AuthType: authType, AppID: options.AppID, AppKey: options.AppKey, InstallationID: options.InstallationID,
+			RepoProperties: properties}, nil
+	})
+	return hostedClient{client}, err
+}
+
+// NewGitLab returns a client creating tags and releases on GitLab
+func NewGitLab(options GitLabOptions) (Client, error) {
+	if options.Repo == "" {
+		return nil, errors.New("a GitLab repo is required")
+	}
+	if !gitlab.ValidAuthType(options.TokenType) {
+		return nil, errors.New("invalid GitLab token type " + options.TokenType)
+	}
+	client, err := newProviderClient(options.HTTP, func(properties tag.RepoProperties) (tagProvider, error) {
+		properties.Password = options.Token
+		return &gitlab.Properties{Repo: options.Repo, Host: strings.TrimSuffix(options.Host, "/"),
+			AuthType: options.TokenType, RepoProperties: properties}, nil
+	})
+	return hostedClient{client}, err
+}
+
+// NewBitbucket returns a client creating tags on Bitbucket Cloud or Bitbucket Server
+func NewBitbucket(options BitbucketOptions) (Client, error) {
+	if options.Repo == "" {
+		return nil, errors.New("a Bitbucket repo is required")
+	}
+	if options.Host != "" {
+		if _, err := bitbucket.ServerRepoPath(options.Repo); err != nil {
+			return nil, err
+		}
+	}
+	client, err := newProviderClient(options.HTTP, func(properties tag.RepoProperties) (tagProvider, error) {
+		properties.Password = options.Password
+		return &bitbucket.Properties{Username: options.Username, Repo: options.Repo, Host: strings.TrimSuffix(options.Host, "/"),
+			AuthType: authType(options.Username, bitbucket.AuthBasic, bitbucket.AuthBearer), RepoProperties: properties}, nil
+	})
+	return hostedClient{client}, err
+}
+
+// NewGit returns a client pushing annotated or lightweight tags to the origin with git. The refs of the origin are
+// listed once for calls with the same tag name and hash until the tag is created, the calls run one at a time
+func NewGit(options GitOptions) (Client, error) {
+	if options.Origin == "" && options.RepoPath == "" {
+		return nil, errors.New("an origin or repository path is required")
+	}
+	if !git.ValidSignFormat(options.SignFormat) {
+		return nil, errors.New("invalid sign format " + options.SignFormat)
+	}
+	httpAuth := git.HTTPAuthBasic
+	if options.BearerToken {
+		httpAuth = git.HTTPAuthBearer
+	}
+	repository := func(properties tag.RepoProperties) *git.Properties {
+		properties.Password = options.Password
+		return &git.Properties{
+			Username:              options.Username,
+			Email:                 options.Email,
+			Origin:                options.Origin,
+			RepoPath:              options.RepoPath,
+			SSH:                   options.SSHKey,
+			HTTPAuth:              httpAuth,
+			KnownHosts:            options.KnownHosts,
+			InsecureIgnoreHostKey: options.InsecureIgnoreHostKey,
+			TaggerName:            options.TaggerName,
+			TaggerEmail:           options.TaggerEmail,
+			Lightweight:           options.Lightweight,
+			SignKey:               options.SignKey,
+			SignFormat:            options.SignFormat,
+			SignPassword:          options.SignPassword,
+			RepoProperties:        properties,
+		}
+	}
+	tags := &gitTags{list: (*git.Properties).InitializeRepository}
+	return gitClient{providerClient: providerClient{provider: func(properties tag.RepoProperties) (tagProvider, error) {
+		return tags.open(repository, properties)
+	}}, repository: repository, files: &gitFiles{}, tags: tags}, nil
+}
+
+// open returns the repository listed for the tag and hash, listing the refs of the origin when it is a different tag
+func (t *gitTags) open(repository func(properties tag.RepoProperties) *git.Properties, properties tag.RepoProperties) (tagProvider, error) {
+	key := properties.Tag + " " + properties.Hash
+	if t.provider == nil || t.key != key {
+		t.provider = nil
+		provider := repository(properties)
+		err := t.list(provider)
+		if err != nil {
+			return nil, err
+		}
+		t.key, t.provider = key, provider
+	}
+	t.provider.Context = properties.Context
+	t.provider.Body = properties.Body
+	return t.provider, nil
+}
+
+// newProviderClient uses the client of the options, or creates one with their timeout and retry policy
+func newProviderClient(options HTTPOptions, provider func(properties tag.RepoProperties) (tagProvider, error)) (providerClient, error) {
+	client := options.Client
+	if client == nil {
+		var err error
+		client, err = tag.NewHTTPClient(tag.ClientOptions{Timeout: options.Timeout, Retry: tag.RetryPolicy(options.Retry)})
+		if err != nil {
+			return providerClient{}, err
+		}
+	}
+	return providerClient{client: client, retry: options.Retry, provider: provider}, nil
+}
+
+// ValidateTag reports whether the tag exists with the provider
+func (p providerClient) ValidateTag(ctx context.Context, tag Tag) (TagState, error) {
+	provider, err := p.provider(p.properties(ctx, tag))
+	if err != nil {
+		return TagMissing, &ProviderError{Err: ErrCheckTag, Message: err.Error()}
+	}
+	state := provider.ValidateTag()
+	switch {
+	case state.Unknown:
+		return TagMissing, &ProviderError{Err: ErrCheckTag, StatusCode: state.StatusCode, Message: state.Message}
+	case state.TagDoesntExist:
+		return TagMissing, nil
+	case state.TagExistsWithProvidedHash:
+		return TagAtHash, nil
+	}
+	return TagAtOtherCommit, nil
+}
+
+// CreateTag creates the tag with the provider
+func (p providerClient) CreateTag(ctx context.Context, tag Tag) error {
+	provider, err := p.provider(p.properties(ctx, tag))
+	if err != nil {
+		return &ProviderError{Err: ErrCreateTag, Message: err.Error()}
+	}
+	if !provider.CreateTag() {
+		failure := provider.Failure()
+		return &ProviderError{Err: ErrCreateTag, StatusCode: failure.StatusCode, Message: failure.Message}
+	}
+	return nil
+}
+
+// ChangedFiles lists the files changed since the existing tag with the provider
+func (p providerClient) ChangedFiles(ctx context.Context, tag Tag) ([]string, error) {
+	provider, err := p.provider(p.properties(ctx, tag))
+	if err != nil {
+		return nil, err
+	}
+	return provider.ChangedFiles(strings.TrimSpace(tag.Name))
+}
+
+// ReadFile reads a file of the repository with the provider API
+func (p providerClient) ReadFile(ctx context.Context, ref string, path string) (string, error) {
+	provider, err := p.provider(p.properties(ctx, Tag{}))
+	if err != nil {
+		return "", err
+	}
+	return provider.ReadFile(ref, path)
+}
+
+func (p providerClient) properties(ctx context.Context, release Tag) tag.RepoProperties {
+	return tag.RepoProperties{
+		Tag:     strings.TrimSpace(release.Name),
+		Hash:    release.Hash,
+		Body:    release.Notes,
+		Client:  p.client,
+		Context: ctx,
+		Retry:   tag.RetryPolicy(p.retry),
+	}
+}
+
+// ReleaseURL is the page of the release with the provider
+func (h hostedClient) ReleaseURL(release Tag) string {
+	provider, err := h.provider(h.properties(context.Background(), release))
+	if err != nil {
+		return ""
+	}
+	return provider.(tag.ReleaseLinker).ReleaseURL()
+}
+
+// UpsertComment comments on the pull request with the provider API
+func (h hostedClient) UpsertComment(ctx context.Context, pullRequest int, marker string, body string) error {
+	provider, err := h.provider(h.properties(ctx, Tag{}))
+	if err != nil {
+		return err
+	}
+	return provider.(tag.Commenter).UpsertComment(pullRequest, marker, body)
+}
+
+// ValidateTag reports whether the tag exists in the origin
+func (g gitClient) ValidateTag(ctx context.Context, release Tag) (TagState, error) {
+	g.tags.Lock()
+	defer g.tags.Unlock()
+	return g.providerClient.ValidateTag(ctx, release)
+}
+
+// CreateTag pushes the tag to the origin
+func (g gitClient) CreateTag(ctx context.Context, release Tag) error {
+	g.tags.Lock()
+	defer g.tags.Unlock()
+	defer func() { g.tags.provider = nil }()
+	return g.providerClient.CreateTag(ctx, release)
+}
+
+// ChangedFiles lists the files changed since the existing tag in the origin
+func (g gitClient) ChangedFiles(ctx context.Context, release Tag) ([]string, error) {
+	g.tags.Lock()
+	defer g.tags.Unlock()
+	return g.providerClient.ChangedFiles(ctx, release)
+}
+
+// ReadFile reads a file of the repository, fetching branches and commits missing from it from the origin
+func (g gitClient) ReadFile(ctx context.Context, ref string, path string) (string, error) {
+	g.files.Lock()
+	defer g.files.Unlock()
+	if g.files.provider == nil {
+		provider := g.repository(tag.RepoProperties{})
+		err := provider.OpenRepository()
+		if err != nil {
+			return "", err
+		}
+		g.files.provider = provider
+	}
+	g.files.provider.Context = ctx
+	return g.files.provider.ReadFile(ref, path)
+}
+
+// VerifyTag checks the signature of the existing tag in the origin
+func (g gitClient) VerifyTag(ctx context.Context, release Tag, keyring string) error {
+	g.tags.Lock()
+	defer g.tags.Unlock()
+	provider, err := g.provider(g.properties(ctx, release))
+	if err != nil {
+		return err
+	}
+	return provider.(*git.Properties).VerifyTag(keyring)
+}
+
+// authType is basic authentication with a username, otherwise the token is sent as a bearer token
+func authType(username string, basic string, bearer string) string {
+	if username == "" {
+		return bearer
+	}
+	return basic
+}
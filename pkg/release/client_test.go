@@ -0,0 +1,171 @@
+package release
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	gitprovider "github.com/sanjP10/release/internal/tag/providers/git"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const hash = "e1db5e6db25ec6a8592c879d3ff3435c5503d03d"
+
+func TestGitHubClient(t *testing.T) {
+	assertTest := assert.New(t)
+	created := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/api/v3/repos/owner/repo/git/refs/tags/1.0.0" && !created:
+			w.WriteHeader(http.StatusNotFound)
+		case r.Method == "GET" && r.URL.Path == "/api/v3/repos/owner/repo/git/refs/tags/1.0.0":
+			_, _ = w.Write([]byte(`{"object": {"sha": "` + hash + `"}}`))
+		case r.Method == "POST" && r.URL.Path == "/api/v3/repos/owner/repo/releases":
+			body := map[string]interface{}{}
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			if body["tag_name"] == "3.0.0" {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				_, _ = w.Write([]byte(`{"message": "Validation Failed", "errors": [{"code": "invalid"}]}`))
+				return
+			}
+			assert.Equal(t, "* notes", body["body"])
+			created = true
+			w.WriteHeader(http.StatusCreated)
+		case r.URL.Path == "/api/v3/repos/owner/repo/git/refs/tags/2.0.0":
+			w.WriteHeader(http.StatusForbidden)
+		case r.URL.Path == "/api/v3/repos/owner/repo/git/refs/tags/3.0.0":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewGitHub(GitHubOptions{Repo: "owner/repo", Host: server.URL, Token: "token"})
+	assertTest.NoError(err)
+	ctx := context.Background()
+	release := Tag{Name: "1.0.0", Hash: hash, Notes: "* notes"}
+	state, err := client.ValidateTag(ctx, release)
+	assertTest.NoError(err)
+	assertTest.Equal(TagMissing, state)
+	assertTest.Equal(server.URL+"/owner/repo/releases/tag/1.0.0", client.(ReleaseLinker).ReleaseURL(release))
+	assertTest.NoError(client.CreateTag(ctx, release))
+	state, err = client.ValidateTag(ctx, release)
+	assertTest.NoError(err)
+	assertTest.Equal(TagAtHash, state)
+	state, err = client.ValidateTag(ctx, Tag{Name: "1.0.0", Hash: "other"})
+	assertTest.NoError(err)
+	assertTest.Equal(TagAtOtherCommit, state)
+
+	_, err = client.ValidateTag(ctx, Tag{Name: "2.0.0", Hash: hash})
+	var providerErr *ProviderError
+	assertTest.ErrorAs(err, &providerErr)
+	assertTest.ErrorIs(err, ErrCheckTag)
+	assertTest.Equal(http.StatusForbidden, providerErr.StatusCode)
+
+	// the provider explains why the tag was not created
+	err = client.CreateTag(ctx, Tag{Name: "3.0.0", Hash: hash})
+	assertTest.ErrorIs(err, ErrCreateTag)
+	assertTest.EqualError(err, "unable to create tag, status 422: invalid")
+}
+
+func TestGitHubClientFilesAndComments(t *testing.T) {
+	assertTest := assert.New(t)
+	comments := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/api/v3/repos/owner/repo/contents/CHANGELOG.md" && r.URL.Query().Get("ref") == "main":
+			_, _ = w.Write([]byte("## 1.0.0"))
+		case r.Method == "GET" && r.URL.Path == "/api/v3/repos/owner/repo/contents/missing.md":
+			w.WriteHeader(http.StatusNotFound)
+		case r.Method == "GET" && r.URL.Path == "/api/v3/repos/owner/repo/issues/1/comments":
+			_, _ = w.Write([]byte(`[]`))
+		case r.Method == "POST" && r.URL.Path == "/api/v3/repos/owner/repo/issues/1/comments":
+			comments++
+			w.WriteHeader(http.StatusCreated)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	// a trailing slash on the host is ignored
+	client, err := NewGitHub(GitHubOptions{Repo: "owner/repo", Host: server.URL + "/", Token: "token"})
+	assertTest.NoError(err)
+	ctx := context.Background()
+	content, err := client.(FileReader).ReadFile(ctx, "main", "CHANGELOG.md")
+	assertTest.NoError(err)
+	assertTest.Equal("## 1.0.0", content)
+	_, err = client.(FileReader).ReadFile(ctx, "main", "missing.md")
+	assertTest.ErrorIs(err, ErrFileNotFound)
+	assertTest.NoError(client.(Commenter).UpsertComment(ctx, 1, "<!-- marker -->", "<!-- marker -->\nbody"))
+	assertTest.Equal(1, comments)
+}
+
+func TestNewClientRequiresRepo(t *testing.T) {
+	assertTest := assert.New(t)
+	_, err := NewGitHub(GitHubOptions{})
+	assertTest.Error(err)
+	_, err = NewGitLab(GitLabOptions{Repo: "group/project", TokenType: "password"})
+	assertTest.Error(err)
+	_, err = NewBitbucket(BitbucketOptions{Repo: "repo", Host: "https://bitbucket.example.com"})
+	assertTest.Error(err)
+	_, err = NewGit(GitOptions{})
+	assertTest.Error(err)
+}
+
+func TestGitClient(t *testing.T) {
+	assertTest := assert.New(t)
+	originPath := t.TempDir()
+	_, err := git.PlainInit(originPath, true)
+	assertTest.NoError(err)
+	clonePath := t.TempDir()
+	clone, err := git.PlainInit(clonePath, false)
+	assertTest.NoError(err)
+	worktree, _ := clone.Worktree()
+	commit, err := worktree.Commit("initial commit", &git.CommitOptions{
+		AllowEmptyCommits: true,
+		Author:            &object.Signature{Name: "tester", Email: "tester@example.com", When: time.Now()},
+	})
+	assertTest.NoError(err)
+	_, err = clone.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{originPath}})
+	assertTest.NoError(err)
+	assertTest.NoError(clone.Push(&git.PushOptions{RemoteName: "origin", RefSpecs: []config.RefSpec{"refs/heads/*:refs/heads/*"}}))
+
+	client, err := NewGit(GitOptions{Origin: originPath, Username: "tester", Email: "tester@example.com"})
+	assertTest.NoError(err)
+	tags := client.(gitClient).tags
+	list := tags.list
+	lists := 0
+	tags.list = func(provider *gitprovider.Properties) error {
+		lists++
+		return list(provider)
+	}
+	release := Tag{Name: "1.0.0", Hash: commit.String(), Notes: "* notes"}
+	state, err := client.ValidateTag(context.Background(), release)
+	assertTest.NoError(err)
+	assertTest.Equal(TagMissing, state)
+	assertTest.NoError(client.CreateTag(context.Background(), release))
+	// the refs listed to validate the tag are reused to create it
+	assertTest.Equal(1, lists)
+	state, err = client.ValidateTag(context.Background(), release)
+	assertTest.NoError(err)
+	assertTest.Equal(TagAtHash, state)
+	assertTest.Equal(2, lists)
+	_, ok := client.(ReleaseLinker)
+	assertTest.False(ok)
+	_, ok = client.(Commenter)
+	assertTest.False(ok)
+	_, err = client.(FileReader).ReadFile(context.Background(), "master", "CHANGELOG.md")
+	assertTest.ErrorIs(err, ErrFileNotFound)
+
+	// the tag at another commit is the cause of the failure
+	err = client.CreateTag(context.Background(), Tag{Name: "1.0.0", Hash: hash})
+	assertTest.EqualError(err, "unable to create tag: tag already exists at another commit")
+}
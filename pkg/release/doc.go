@@ -0,0 +1,10 @@
+// Package release reads changelogs, compares versions and validates or creates release tags with Git providers.
+//
+// It is the library behind the release command line tool, for Go programs that would otherwise run the binary.
+//
+// # Stability
+//
+// The exported API of this package follows the semantic versioning of the module: it only changes in backwards
+// incompatible ways in a new major version, new minor versions only add to it. Everything under internal may change
+// at any time.
+package release
@@ -0,0 +1,69 @@
+package release_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/sanjP10/release/pkg/release"
+	"os"
+	"time"
+)
+
+func ExampleParseChangelog() {
+	changelog := release.ParseChangelog(`# Changelog
+
+## 1.1.0
+### Fixed
+* Tags with slashes
+
+## 1.0.0
+### Added
+* Initial release
+`)
+	latest, _ := changelog.Latest()
+	fmt.Println(latest.Version)
+	fmt.Println(latest.Notes)
+	fmt.Println(changelog.Validate())
+	// Output:
+	// 1.1.0
+	// ### Fixed
+	// * Tags with slashes
+	// <nil>
+}
+
+func ExampleCompareVersions() {
+	compared, _ := release.CompareVersions("1.10.0", "1.9.0")
+	fmt.Println(compared)
+	// Output: 1
+}
+
+func ExampleClient() {
+	changelog, err := release.ReadChangelog("CHANGELOG.md")
+	if err == nil {
+		err = changelog.Validate()
+	}
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	latest, _ := changelog.Latest()
+	client, err := release.NewGitHub(release.GitHubOptions{
+		Repo:  "owner/repo",
+		Token: os.Getenv("GITHUB_TOKEN"),
+		HTTP:  release.HTTPOptions{Timeout: time.Minute, Retry: release.RetryPolicy{MaxRetries: 3, BaseDelay: time.Second}},
+	})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	tag := release.Tag{Name: "v" + latest.Version, Hash: os.Getenv("GITHUB_SHA"), Notes: latest.Notes}
+	state, err := client.ValidateTag(context.Background(), tag)
+	switch {
+	case err != nil:
+		fmt.Println(err)
+	case state == release.TagAtOtherCommit:
+		fmt.Println("add a new version to the changelog")
+	case state == release.TagMissing:
+		err = client.CreateTag(context.Background(), tag)
+		fmt.Println(err)
+	}
+}
@@ -0,0 +1,20 @@
+package release
+
+import (
+	"fmt"
+	"github.com/hashicorp/go-version"
+)
+
+// CompareVersions returns -1, 0 or 1 when version a is lower than, equal to or greater than version b. Versions are
+// semantic versions with any number of segments, prereleases sort before their release
+func CompareVersions(a string, b string) (int, error) {
+	first, err := version.NewVersion(a)
+	if err != nil {
+		return 0, fmt.Errorf("invalid version %s: %w", a, err)
+	}
+	second, err := version.NewVersion(b)
+	if err != nil {
+		return 0, fmt.Errorf("invalid version %s: %w", b, err)
+	}
+	return first.Compare(second), nil
+}
@@ -6,6 +6,8 @@ import (
 	"github.com/google/subcommands"
 	"github.com/sanjP10/release/internal/commands"
 	"os"
+	"os/signal"
+	"syscall"
 )
 
 func main() {
@@ -13,8 +15,18 @@ func main() {
 	subcommands.Register(subcommands.CommandsCommand(), "")
 	subcommands.Register(&commands.Validate{}, "")
 	subcommands.Register(&commands.Create{}, "")
+	subcommands.Register(&commands.CheckPR{}, "")
+	subcommands.Register(&commands.Preview{}, "")
+	subcommands.Register(&commands.SyncVersion{}, "")
 	subcommands.Register(&commands.Version{}, "")
 	flag.Parse()
-	ctx := context.Background()
-	os.Exit(int(subcommands.Execute(ctx)))
+	// cancel in flight requests on the first signal, a second signal exits immediately
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	exit := subcommands.Execute(ctx)
+	stop()
+	os.Exit(int(exit))
 }